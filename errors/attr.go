@@ -0,0 +1,503 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var (
+	// DefaultSourceSlogKey is the key WrapAttr uses for the file:line of the first attrError in a chain.
+	// Set it to "" to disable adding the source attr.
+	DefaultSourceSlogKey = slog.SourceKey
+	// DefaultMsgSlogKey is the key LogValue uses for the error message.
+	DefaultMsgSlogKey = slog.MessageKey
+	// DefaultAttrPrecedence decides which value UnwrapAttr and LogValue keep when a key is found more than once within an error chain.
+	DefaultAttrPrecedence = InnermostAttr
+	// AttrFilterFunc decides which attrs LogValue includes, dropping any attr it returns false for.
+	// Use it to enforce a logging policy, such as never logging slog.KindAny values that may hold large structs.
+	// Every attr is kept when it's nil. UnwrapAttr is unaffected.
+	AttrFilterFunc func(slog.Attr) bool
+	// OnDuplicateKey is called by UnwrapAttr and LogValue whenever a key is found more than once within an error chain,
+	// since DefaultAttrPrecedence silently drops one of the values. old was found first and new after it, outside-in.
+	// Set it to log or fail a test on collisions. Duplicates are silently resolved when it's nil.
+	OnDuplicateKey func(key string, old, new slog.Value)
+	// DefaultFuncSlogKey is the key WrapAttr uses for the package.func of the first attrError in a chain, like DefaultSourceSlogKey,
+	// so logs can be filtered by the originating function without parsing the message. It's disabled by default with "".
+	DefaultFuncSlogKey = ""
+	// DefaultCtxReasonSlogKey is the key WrapAttrCtx and WrapCtxErr use to tell a context.DeadlineExceeded error ("deadline")
+	// from a context.Canceled error ("canceled"), such as for paging on timeouts but not cancellations.
+	// Set it to "" to disable adding the attr.
+	DefaultCtxReasonSlogKey = "ctx_reason"
+)
+
+// AttrPrecedence decides which value wins when the same attr key is found at different depths of an error chain.
+// Within a single WrapAttr call, the last attr with a key always wins.
+// The branches of a joined error are treated as if each branch is within the previous one,
+// so InnermostAttr prefers the last branch and OutermostAttr prefers the first.
+type AttrPrecedence uint8
+
+const (
+	// InnermostAttr keeps the value closest to the original error, ignoring values re-added by outer wraps.
+	InnermostAttr AttrPrecedence = iota
+	// OutermostAttr keeps the value from the wrap closest to the caller, letting outer wraps override inner values.
+	OutermostAttr
+)
+
+// attrError wraps an error with slog.Attr metadata.
+// It implements slog.LogValuer, so logging an error with log/slog includes the metadata of the entire chain.
+type attrError struct {
+	error
+	record slog.Record
+	// origin are the keys within record that the errors it joins can't override, so a Join's source wins over its branches'.
+	origin []string
+}
+
+func (e attrError) Unwrap() error { return e.error }
+
+// Is reports whether target is an attrError wrapping an error within e's chain.
+// attrError isn't comparable, so without Is an attrError used as a sentinel would never match in errors.Is.
+// Other targets are left to errors.Is, which already compares them against every error within the chain.
+func (e attrError) Is(target error) bool {
+	t, ok := target.(attrError)
+	return ok && errors.Is(e.error, t.error)
+}
+
+// LogValue returns a group containing the error message and every attr found within the error chain, sorted by key.
+// Attrs rejected by AttrFilterFunc are left out.
+func (e attrError) LogValue() slog.Value {
+	return slog.GroupValue(append([]slog.Attr{slog.String(DefaultMsgSlogKey, e.Error())}, loggedAttrs(e)...)...)
+}
+
+// GoString returns Go syntax that would recreate e, such as errors.WrapAttr(errors.New("msg"), slog.String("k", "v")), for %#v in debugging and test failures.
+// Errors within the chain without attrs are shown as errors.New with their message. Every attr is shown, including the ones added automatically under DefaultSourceSlogKey.
+func (e attrError) GoString() string {
+	args := []string{goStringCause(e.error)}
+	e.record.Attrs(func(a slog.Attr) bool {
+		args = append(args, goStringAttr(a))
+		return true
+	})
+	return fmt.Sprintf("errors.WrapAttr(%s)", strings.Join(args, ", "))
+}
+
+// goStringCause returns the GoString of the error an attrError wraps, skipping the package.func prefix wrapAttr adds.
+func goStringCause(err error) string {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := make([]string, 0, len(joined.Unwrap()))
+		for _, e := range joined.Unwrap() {
+			errs = append(errs, goStringErr(e))
+		}
+		return fmt.Sprintf("errors.Join(%s)", strings.Join(errs, ", "))
+	}
+	if inner := errors.Unwrap(err); inner != nil {
+		return goStringErr(inner)
+	}
+	return goStringErr(err)
+}
+
+func goStringErr(err error) string {
+	if gs, ok := err.(fmt.GoStringer); ok {
+		return gs.GoString()
+	}
+	return fmt.Sprintf("errors.New(%q)", err.Error())
+}
+
+func goStringAttr(a slog.Attr) string {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return fmt.Sprintf("slog.String(%q, %q)", a.Key, v.String())
+	case slog.KindInt64:
+		return fmt.Sprintf("slog.Int64(%q, %d)", a.Key, v.Int64())
+	case slog.KindUint64:
+		return fmt.Sprintf("slog.Uint64(%q, %d)", a.Key, v.Uint64())
+	case slog.KindFloat64:
+		return fmt.Sprintf("slog.Float64(%q, %v)", a.Key, v.Float64())
+	case slog.KindBool:
+		return fmt.Sprintf("slog.Bool(%q, %t)", a.Key, v.Bool())
+	case slog.KindDuration:
+		return fmt.Sprintf("slog.Duration(%q, %d)", a.Key, v.Duration())
+	case slog.KindTime:
+		return fmt.Sprintf("slog.Time(%q, %#v)", a.Key, v.Time())
+	case slog.KindGroup:
+		args := []string{fmt.Sprintf("%q", a.Key)}
+		for _, ga := range v.Group() {
+			args = append(args, goStringAttr(ga))
+		}
+		return fmt.Sprintf("slog.Group(%s)", strings.Join(args, ", "))
+	default:
+		return fmt.Sprintf("slog.Any(%q, %#v)", a.Key, v.Any())
+	}
+}
+
+// AttrsString returns only the attrs err would log, without its message, such as for diffing the metadata of two errors.
+// The attrs are sorted by key and formatted like "id=1 table=users". AttrFilterFunc applies like it does to LogValue.
+func AttrsString(err error) string {
+	var b strings.Builder
+	for i, a := range loggedAttrs(err) {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(a.String())
+	}
+	return b.String()
+}
+
+// loggedAttrs returns the attrs of err's chain sorted by key, without the ones AttrFilterFunc drops.
+func loggedAttrs(err error) []slog.Attr {
+	meta := UnwrapAttr(err)
+	keys := slices.Sorted(maps.Keys(meta))
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, k := range keys {
+		a := slog.Attr{Key: k, Value: meta[k]}
+		if AttrFilterFunc == nil || AttrFilterFunc(a) {
+			attrs = append(attrs, a)
+		}
+	}
+	return attrs
+}
+
+// WrapAttr wraps an error with the caller's package.func prepended and the given slog.Attr attached as metadata.
+// The first WrapAttr in a chain also attaches the caller's file:line under DefaultSourceSlogKey, and its package.func under DefaultFuncSlogKey if set.
+// Like Wrap, it returns nil if err is nil.
+func WrapAttr(err error, attrs ...slog.Attr) error {
+	return wrapAttr(err, 3, attrs)
+}
+
+// AppendAttr adds attrs to err without wrapping it again, for adding attrs repeatedly at the same layer without lengthening the chain.
+// If err is an attrError, such as one returned by WrapAttr, attrs are appended to a copy of its attrs, so err itself is unchanged.
+// Otherwise err is wrapped exactly like WrapAttr, with the caller's package.func prepended.
+// Appended attrs win over the same keys already within that attrError, like the last attr within a single WrapAttr call.
+// Like WrapAttr, it returns nil if err is nil.
+func AppendAttr(err error, attrs ...slog.Attr) error {
+	if err == nil {
+		return nil
+	}
+	if ae, ok := err.(attrError); ok {
+		r := ae.record.Clone()
+		r.AddAttrs(attrs...)
+		return attrError{error: ae.error, record: r, origin: ae.origin}
+	}
+	return wrapAttr(err, 3, attrs)
+}
+
+// WrapAttrWithSkip is WrapAttr with the package.func and file:line of the desired caller, for library code wrapping errors on behalf of its callers.
+// skip is like ErrorfWithSkip's, so 2 is the caller of WrapAttrWithSkip and 3 is the caller of that.
+func WrapAttrWithSkip(err error, skip int, attrs ...slog.Attr) error {
+	return wrapAttr(err, skip+1, attrs)
+}
+
+// WrapAttrCtxWithSkip is WrapAttrCtx with the package.func and file:line of the desired caller, with skip like WrapAttrWithSkip.
+func WrapAttrCtxWithSkip(ctx context.Context, err error, skip int, attrs ...slog.Attr) error {
+	return wrapAttr(err, skip+1, ctxAttrs(ctx, err, attrs))
+}
+
+// WrapAttrGroup is WrapAttr, but nests attrs within a group so their keys can't collide with other attrs in the chain.
+func WrapAttrGroup(err error, group string, attrs ...slog.Attr) error {
+	return wrapAttr(err, 3, []slog.Attr{{Key: group, Value: slog.GroupValue(attrs...)}})
+}
+
+// NewGroup is New with attrs nested within a group attached as metadata, along with the caller's file:line.
+func NewGroup(text, group string, attrs ...slog.Attr) error {
+	fn, file, line, ok := caller(2)
+	msg := ""
+	if ok {
+		msg = fn + " " + text
+	}
+	err := errors.New(msg)
+	var r slog.Record
+	r.AddAttrs(appendOriginAttrs(err, []slog.Attr{{Key: group, Value: slog.GroupValue(attrs...)}}, fn, file, line, ok)...)
+	return attrError{error: err, record: r}
+}
+
+// WrapAttrCtx is like WrapAttr, but also attaches the attrs added to ctx by AddAttrToCtx.
+// Attrs passed directly take precedence over ctx attrs with the same key.
+// If err is a context error, the DefaultCtxReasonSlogKey attr is attached too, like WrapCtxErr.
+func WrapAttrCtx(ctx context.Context, err error, attrs ...slog.Attr) error {
+	return wrapAttr(err, 3, ctxAttrs(ctx, err, attrs))
+}
+
+// WrapAttrCtxAndAdd wraps err with WrapAttrCtx and returns a ctx with the same attrs added by AddAttrToCtx,
+// keeping the error's metadata in sync with the ctx used for later logging or wrapping.
+// The returned ctx has the attrs added even when err is nil.
+func WrapAttrCtxAndAdd(ctx context.Context, err error, attrs ...slog.Attr) (error, context.Context) {
+	return wrapAttr(err, 3, ctxAttrs(ctx, err, attrs)), AddAttrToCtx(ctx, attrs...)
+}
+
+// WrapAttrCtxAfter is WrapAttrCtx for deferring at the top of a function with a named error return,
+// wrapping the final value of *errPtr in place unless it's nil.
+// *errPtr is only read once the deferred call runs, so any assignment to err before then is wrapped,
+// including one made by a recover deferred after WrapAttrCtxAfter, since deferred calls run in reverse order.
+//
+//	func Query(ctx context.Context, id int) (err error) {
+//		defer errors.WrapAttrCtxAfter(ctx, &err, slog.Int("id", id))
+func WrapAttrCtxAfter(ctx context.Context, errPtr *error, attrs ...slog.Attr) {
+	if errPtr == nil || *errPtr == nil {
+		return
+	}
+	*errPtr = wrapAttr(*errPtr, 3, ctxAttrs(ctx, *errPtr, attrs))
+}
+
+// WrapAttrCtxAfterGroup is WrapAttrCtxAfter, but nests the ctx attrs and attrs within a group.
+// Keeping each package's attrs in their own group, such as "db" or "http", prevents their keys from colliding.
+func WrapAttrCtxAfterGroup(ctx context.Context, group string, errPtr *error, attrs ...slog.Attr) {
+	if errPtr == nil || *errPtr == nil {
+		return
+	}
+	*errPtr = wrapAttr(*errPtr, 3, append(ctxReasonAttr(*errPtr), slog.Attr{Key: group, Value: slog.GroupValue(append(attrsFromCtx(ctx), attrs...)...)}))
+}
+
+// WrapCtxErr is WrapAttr, but if err is context.DeadlineExceeded or context.Canceled,
+// it also attaches "deadline" or "canceled" under DefaultCtxReasonSlogKey so log sites needn't check.
+func WrapCtxErr(err error, attrs ...slog.Attr) error {
+	return wrapAttr(err, 3, append(ctxReasonAttr(err), attrs...))
+}
+
+// ctxAttrs returns the attrs for WrapAttrCtx, in order of increasing precedence.
+func ctxAttrs(ctx context.Context, err error, attrs []slog.Attr) []slog.Attr {
+	return append(append(ctxReasonAttr(err), attrsFromCtx(ctx)...), attrs...)
+}
+
+// ctxReasonAttr returns the DefaultCtxReasonSlogKey attr for err if it's a context error the chain doesn't already have an attr for.
+func ctxReasonAttr(err error) []slog.Attr {
+	if DefaultCtxReasonSlogKey == "" {
+		return nil
+	}
+	var reason string
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		reason = "deadline"
+	case errors.Is(err, context.Canceled):
+		reason = "canceled"
+	default:
+		return nil
+	}
+	if chainHasAttr(err, DefaultCtxReasonSlogKey) {
+		return nil
+	}
+	return []slog.Attr{slog.String(DefaultCtxReasonSlogKey, reason)}
+}
+
+// chainHasAttr returns true if an attrError within err's chain has an attr with key, without building the attr map like UnwrapAttr.
+// The keys within a JoinIndexed branch are prefixed, so branches are skipped.
+func chainHasAttr(err error, key string) bool {
+	for err != nil {
+		if _, ok := err.(indexedBranch); ok {
+			return false
+		}
+		var ae attrError
+		if !errors.As(err, &ae) {
+			return false
+		}
+		found := false
+		ae.record.Attrs(func(a slog.Attr) bool {
+			found = a.Key == key
+			return !found
+		})
+		if found {
+			return true
+		}
+		if joined, ok := ae.error.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				if chainHasAttr(e, key) {
+					return true
+				}
+			}
+			return false
+		}
+		err = errors.Unwrap(ae.error)
+	}
+	return false
+}
+
+type attrCtxKey struct{}
+
+// AddAttrToCtx returns a ctx carrying attrs for WrapAttrCtx to attach to errors, in addition to any attrs already added.
+func AddAttrToCtx(ctx context.Context, attrs ...slog.Attr) context.Context {
+	if len(attrs) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, attrCtxKey{}, append(attrsFromCtx(ctx), attrs...))
+}
+
+// MergeAttrInCtx is like AddAttrToCtx, but attrs with a key already in ctx are dropped so the first value added wins.
+// This keeps middleware layers adding the same key from overwriting each other.
+// When built with the debug tag, each dropped attr is logged as a warning with slog.Default.
+func MergeAttrInCtx(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := attrsFromCtx(ctx)
+	merged := existing
+	for _, a := range attrs {
+		i := slices.IndexFunc(merged, func(e slog.Attr) bool { return e.Key == a.Key })
+		if i == -1 {
+			merged = append(merged, a)
+		} else if debugBuild {
+			slog.WarnContext(ctx, "errors.MergeAttrInCtx dropped duplicate attr", "key", a.Key, "kept", merged[i].Value, "dropped", a.Value)
+		}
+	}
+	if len(merged) == len(existing) {
+		return ctx
+	}
+	return context.WithValue(ctx, attrCtxKey{}, merged)
+}
+
+// attrsFromCtx returns the attrs added to ctx by AddAttrToCtx. The result is safe to append to.
+func attrsFromCtx(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrCtxKey{}).([]slog.Attr)
+	return slices.Clip(attrs)
+}
+
+// UnwrapAttr returns every attr within the error chain, including the branches of joined errors.
+// DefaultAttrPrecedence decides which value is kept when the same key is found more than once.
+func UnwrapAttr(err error) map[string]slog.Value {
+	meta := make(map[string]slog.Value)
+	updateAttrMapFromErr(err, meta)
+	return meta
+}
+
+// AttrMap is UnwrapAttr converted into plain Go types, for loggers that don't support log/slog such as logrus.WithFields.
+// Values become string, int64, uint64, float64, bool, time.Duration, time.Time or whatever was given to slog.Any,
+// and slog.LogValuer values are resolved first. Groups are flattened into dotted keys, so slog.Group("db", slog.String("table", "users"))
+// becomes "db.table": "users".
+func AttrMap(err error) map[string]any {
+	meta := UnwrapAttr(err)
+	m := make(map[string]any, len(meta))
+	for k, v := range meta {
+		addToAttrMap(m, k, v)
+	}
+	return m
+}
+
+func addToAttrMap(m map[string]any, key string, v slog.Value) {
+	v = v.Resolve()
+	if v.Kind() != slog.KindGroup {
+		m[key] = v.Any()
+		return
+	}
+	for _, a := range v.Group() {
+		if key != "" && a.Key != "" {
+			addToAttrMap(m, key+"."+a.Key, a.Value)
+		} else {
+			addToAttrMap(m, key+a.Key, a.Value)
+		}
+	}
+}
+
+// updateAttrMapFromErr walks the error chain from the outside in, depth first through joined errors.
+// For InnermostAttr each attr is assigned into meta, so the last one visited wins.
+// For OutermostAttr each record's attrs are visited in reverse and only assigned if missing, so the first record visited wins
+// while the last attr within a record still beats earlier attrs in the same record.
+func updateAttrMapFromErr(err error, meta map[string]slog.Value) {
+	for err != nil {
+		if b, ok := err.(indexedBranch); ok {
+			b.updateAttrMap(meta)
+			return
+		}
+		if ae, ok := err.(attrError); ok {
+			if DefaultAttrPrecedence == OutermostAttr {
+				attrs := make([]slog.Attr, 0, ae.record.NumAttrs())
+				ae.record.Attrs(func(a slog.Attr) bool {
+					attrs = append(attrs, a)
+					return true
+				})
+				for i := len(attrs) - 1; i >= 0; i-- {
+					if old, ok := meta[attrs[i].Key]; !ok {
+						meta[attrs[i].Key] = attrs[i].Value
+					} else if OnDuplicateKey != nil {
+						OnDuplicateKey(attrs[i].Key, old, attrs[i].Value)
+					}
+				}
+			} else {
+				ae.record.Attrs(func(a slog.Attr) bool {
+					if old, ok := meta[a.Key]; ok && OnDuplicateKey != nil {
+						OnDuplicateKey(a.Key, old, a.Value)
+					}
+					meta[a.Key] = a.Value
+					return true
+				})
+			}
+		}
+
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				updateAttrMapFromErr(e, meta)
+			}
+			return
+		}
+		if ae, ok := err.(attrError); ok && len(ae.origin) > 0 {
+			if joined, ok := ae.error.(interface{ Unwrap() []error }); ok {
+				branchMeta := make(map[string]slog.Value)
+				for _, e := range joined.Unwrap() {
+					updateAttrMapFromErr(e, branchMeta)
+				}
+				for _, k := range ae.origin {
+					delete(branchMeta, k)
+				}
+				mergeAttrMap(meta, branchMeta, "")
+				return
+			}
+		}
+		err = errors.Unwrap(err)
+	}
+}
+
+// mergeAttrMap adds the attrs of an inner error within branchMeta to meta with their keys prefixed, following DefaultAttrPrecedence.
+func mergeAttrMap(meta, branchMeta map[string]slog.Value, prefix string) {
+	for k, v := range branchMeta {
+		k = prefix + k
+		old, ok := meta[k]
+		if ok && OnDuplicateKey != nil {
+			OnDuplicateKey(k, old, v)
+		}
+		if !ok || DefaultAttrPrecedence == InnermostAttr {
+			meta[k] = v
+		}
+	}
+}
+
+// wrapAttr is the implementation of WrapAttr. skip is the number of frames between the caller and prependCaller.
+func wrapAttr(err error, skip int, attrs []slog.Attr) error {
+	if err == nil {
+		return nil
+	}
+
+	fn, file, line, ok := caller(skip)
+	format := ""
+	if ok {
+		format = fn + " %w"
+	}
+	var r slog.Record
+	r.AddAttrs(appendOriginAttrs(err, attrs, fn, file, line, ok)...)
+	return attrError{error: fmt.Errorf(format, err), record: r}
+}
+
+// appendOriginAttrs appends the caller's file:line and function name to attrs if their keys are set,
+// unless an attrError within err already has them.
+func appendOriginAttrs(err error, attrs []slog.Attr, fn, file string, line int, ok bool) []slog.Attr {
+	if !ok || (DefaultSourceSlogKey == "" && DefaultFuncSlogKey == "") {
+		return attrs
+	}
+	var ae attrError
+	if errors.As(err, &ae) {
+		return attrs
+	}
+	return append(slices.Clip(attrs), originAttrs(fn, file, line, ok)...)
+}
+
+// originAttrs returns the caller's file:line and function name as attrs if their keys are set.
+func originAttrs(fn, file string, line int, ok bool) []slog.Attr {
+	if !ok {
+		return nil
+	}
+	var attrs []slog.Attr
+	if DefaultSourceSlogKey != "" {
+		attrs = append(attrs, slog.String(DefaultSourceSlogKey, fmt.Sprintf("%s:%d", file, line)))
+	}
+	if DefaultFuncSlogKey != "" {
+		attrs = append(attrs, slog.String(DefaultFuncSlogKey, fn))
+	}
+	return attrs
+}
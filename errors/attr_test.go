@@ -0,0 +1,537 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrapAttrCtx(t *testing.T) {
+	ctx := AddAttrToCtx(context.Background(), slog.String("user", "bob"), slog.Int("id", 1))
+	err := WrapAttrCtx(ctx, io.EOF, slog.Int("id", 2))
+	if !Is(err, io.EOF) {
+		t.Fatalf("expected %v to wrap io.EOF", err)
+	}
+	meta := UnwrapAttr(err)
+	if meta["user"].String() != "bob" || meta["id"].Int64() != 2 {
+		t.Fatalf("unexpected attrs %v", meta)
+	}
+	if WrapAttrCtx(ctx, nil) != nil {
+		t.Fatal("expected nil error")
+	}
+}
+
+func TestWrapAttrCtxAndAdd(t *testing.T) {
+	ctx := AddAttrToCtx(context.Background(), slog.String("user", "bob"))
+	err, newCtx := WrapAttrCtxAndAdd(ctx, io.EOF, slog.String("table", "users"))
+
+	meta := UnwrapAttr(err)
+	if meta["user"].String() != "bob" || meta["table"].String() != "users" {
+		t.Fatalf("unexpected error attrs %v", meta)
+	}
+	ctxAttrs := attrsFromCtx(newCtx)
+	if len(ctxAttrs) != 2 || ctxAttrs[1].Key != "table" || ctxAttrs[1].Value.String() != "users" {
+		t.Fatalf("unexpected ctx attrs %v", ctxAttrs)
+	}
+	if len(attrsFromCtx(ctx)) != 1 {
+		t.Fatalf("original ctx was modified %v", attrsFromCtx(ctx))
+	}
+
+	// The ctx stays in sync with later wraps.
+	meta = UnwrapAttr(WrapAttrCtx(newCtx, io.ErrUnexpectedEOF))
+	if meta["table"].String() != "users" {
+		t.Fatalf("unexpected attrs %v", meta)
+	}
+
+	err, newCtx = WrapAttrCtxAndAdd(ctx, nil, slog.Bool("retry", true))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(attrsFromCtx(newCtx)) != 2 {
+		t.Fatalf("expected attrs added to ctx for a nil error, got %v", attrsFromCtx(newCtx))
+	}
+}
+
+func TestAttrPrecedence(t *testing.T) {
+	defer func(p AttrPrecedence) { DefaultAttrPrecedence = p }(DefaultAttrPrecedence)
+
+	inner := WrapAttr(io.EOF, slog.String("table", "inner"), slog.Int("depth", 0), slog.Int("depth", 1))
+	err := WrapAttr(Wrap(inner), slog.String("table", "outer"))
+	joined := Join(WrapAttr(io.EOF, slog.Int("branch", 0)), WrapAttr(io.EOF, slog.Int("branch", 1)))
+
+	tests := []struct {
+		precedence AttrPrecedence
+		table      string
+		branch     int64
+	}{
+		{InnermostAttr, "inner", 1},
+		{OutermostAttr, "outer", 0},
+	}
+	for _, tt := range tests {
+		DefaultAttrPrecedence = tt.precedence
+		meta := UnwrapAttr(err)
+		if meta["table"].String() != tt.table {
+			t.Fatalf("precedence %d kept table %v", tt.precedence, meta["table"])
+		}
+		// The last attr within the same wrap always wins.
+		if meta["depth"].Int64() != 1 {
+			t.Fatalf("precedence %d kept depth %v", tt.precedence, meta["depth"])
+		}
+		if got := UnwrapAttr(joined)["branch"].Int64(); got != tt.branch {
+			t.Fatalf("precedence %d kept branch %d", tt.precedence, got)
+		}
+	}
+}
+
+func TestAttrErrorIs(t *testing.T) {
+	sentinel := WrapAttr(io.EOF, slog.String("sentinel", "true"))
+	err := WrapAttr(Errorf("reading failed: %w", sentinel), slog.Int("id", 1))
+
+	tests := []struct {
+		target error
+		want   bool
+	}{
+		{sentinel, true},
+		{io.EOF, true},
+		{attrError{error: io.EOF}, true},
+		{WrapAttr(io.EOF), false},
+		{attrError{error: io.ErrUnexpectedEOF}, false},
+		{io.ErrUnexpectedEOF, false},
+	}
+	for i, tt := range tests {
+		if got := Is(err, tt.target); got != tt.want {
+			t.Fatalf("%d: Is(%v, %v) == %t", i, err, tt.target, got)
+		}
+	}
+	if Is(io.EOF, sentinel) {
+		t.Fatal("io.EOF shouldn't match a sentinel wrapping it")
+	}
+}
+
+func TestMergeAttrInCtx(t *testing.T) {
+	ctx := AddAttrToCtx(context.Background(), slog.String("service_name", "logging"))
+	ctx = MergeAttrInCtx(ctx, slog.String("service_name", "tracing"), slog.String("trace_id", "abc"), slog.String("trace_id", "def"))
+
+	meta := UnwrapAttr(WrapAttrCtx(ctx, io.EOF))
+	if meta["service_name"].String() != "logging" || meta["trace_id"].String() != "abc" {
+		t.Fatalf("unexpected attrs %v", meta)
+	}
+	if len(attrsFromCtx(ctx)) != 2 {
+		t.Fatalf("unexpected ctx attrs %v", attrsFromCtx(ctx))
+	}
+	if merged := MergeAttrInCtx(ctx, slog.String("trace_id", "ghi")); merged != ctx {
+		t.Fatal("expected the same ctx when every attr is a duplicate")
+	}
+}
+
+func TestAttrFilterFunc(t *testing.T) {
+	defer func() { AttrFilterFunc = nil }()
+
+	err := WrapAttr(io.EOF, slog.Any("big", struct{ A []int }{}), slog.String("table", "users"), slog.Int("id", 1))
+	AttrFilterFunc = func(a slog.Attr) bool { return a.Value.Kind() != slog.KindAny }
+
+	var keys []string
+	for _, a := range err.(slog.LogValuer).LogValue().Group() {
+		keys = append(keys, a.Key)
+	}
+	if !slices.Equal(keys, []string{DefaultMsgSlogKey, "id", DefaultSourceSlogKey, "table"}) {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+	if _, ok := UnwrapAttr(err)["big"]; !ok {
+		t.Fatal("expected UnwrapAttr to be unfiltered")
+	}
+}
+
+type testLogValuer struct{}
+
+func (testLogValuer) LogValue() slog.Value { return slog.StringValue("resolved") }
+
+func TestAttrMap(t *testing.T) {
+	defer func(key string) { DefaultSourceSlogKey = key }(DefaultSourceSlogKey)
+	DefaultSourceSlogKey = ""
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	err := WrapAttr(io.EOF,
+		slog.String("str", "s"),
+		slog.Int("int", -1),
+		slog.Uint64("uint", 1),
+		slog.Float64("float", 1.5),
+		slog.Bool("bool", true),
+		slog.Duration("dur", time.Second),
+		slog.Time("time", now),
+		slog.Any("any", []int{1}),
+		slog.Any("valuer", testLogValuer{}),
+		slog.Group("db", slog.String("table", "users"), slog.Group("conn", slog.Int("id", 2))),
+	)
+
+	want := map[string]any{
+		"str":        "s",
+		"int":        int64(-1),
+		"uint":       uint64(1),
+		"float":      1.5,
+		"bool":       true,
+		"dur":        time.Second,
+		"time":       now,
+		"any":        []int{1},
+		"valuer":     "resolved",
+		"db.table":   "users",
+		"db.conn.id": int64(2),
+	}
+	if got := AttrMap(err); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected AttrMap\n%#v\nexpected\n%#v", got, want)
+	}
+	if got := AttrMap(nil); len(got) != 0 {
+		t.Fatalf("unexpected AttrMap %v", got)
+	}
+}
+
+func TestWrapAttrCtxAfterFinalValue(t *testing.T) {
+	ctx := AddAttrToCtx(context.Background(), slog.String("request_id", "abc"))
+	errTimeout := &os.PathError{Op: "read", Path: "/tmp/x", Err: os.ErrDeadlineExceeded}
+	query := func(path string) (rows int, err error) {
+		defer WrapAttrCtxAfter(ctx, &err, slog.String("path", path))
+		defer func() {
+			if r := recover(); r != nil {
+				rows, err = 0, fmt.Errorf("recovered: %v", r)
+			}
+		}()
+
+		err = errTimeout
+		switch path {
+		case "ok":
+			err = nil
+			return 1, err
+		case "eof":
+			return 0, io.EOF
+		case "panic":
+			panic("boom")
+		}
+		return 0, err
+	}
+
+	if rows, err := query("ok"); err != nil || rows != 1 {
+		t.Fatalf("expected the nil path to skip wrapping, got %d %v", rows, err)
+	}
+
+	_, err := query("eof")
+	var pathErr *os.PathError
+	if !Is(err, io.EOF) || As(err, &pathErr) {
+		t.Fatalf("expected only the returned io.EOF to be wrapped, got %v", err)
+	}
+
+	_, err = query("slow")
+	if !As(err, &pathErr) || pathErr != errTimeout {
+		t.Fatalf("expected the assigned *os.PathError to be wrapped, got %v", err)
+	}
+
+	_, err = query("panic")
+	if err == nil || !strings.Contains(err.Error(), "recovered: boom") || As(err, &pathErr) {
+		t.Fatalf("expected the recovered error to be wrapped, got %v", err)
+	}
+
+	for _, path := range []string{"eof", "slow", "panic"} {
+		_, err := query(path)
+		if m := AttrMap(err); m["path"] != path || m["request_id"] != "abc" {
+			t.Fatalf("unexpected attrs for %s: %v", path, m)
+		}
+	}
+}
+
+func TestWrapAttrCtxAfterGroup(t *testing.T) {
+	ctx := AddAttrToCtx(context.Background(), slog.String("request_id", "abc"))
+	query := func(fail bool) (err error) {
+		defer WrapAttrCtxAfterGroup(ctx, "db", &err, slog.String("table", "users"))
+		if fail {
+			return io.EOF
+		}
+		return nil
+	}
+	request := func() (err error) {
+		defer WrapAttrCtxAfterGroup(ctx, "http", &err, slog.String("table", "routes"))
+		return query(true)
+	}
+
+	if err := query(false); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	err := request()
+	if !Is(err, io.EOF) {
+		t.Fatalf("expected %v to wrap io.EOF", err)
+	}
+	m := AttrMap(err)
+	if m["db.table"] != "users" || m["http.table"] != "routes" || m["db.request_id"] != "abc" || m["http.request_id"] != "abc" {
+		t.Fatalf("unexpected attrs %v", m)
+	}
+	if _, ok := m["table"]; ok {
+		t.Fatalf("unexpected ungrouped attr in %v", m)
+	}
+}
+
+func TestAttrGroup(t *testing.T) {
+	err := NewGroup("query failed", "db", slog.String("table", "users"), slog.Int("id", 1))
+	if err.Error() != "errors.TestAttrGroup query failed" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	err = WrapAttrGroup(err, "http", slog.String("table", "routes"))
+	if err.Error() != "errors.TestAttrGroup errors.TestAttrGroup query failed" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+
+	meta := UnwrapAttr(err)
+	if meta["db"].Kind() != slog.KindGroup || meta["http"].Kind() != slog.KindGroup {
+		t.Fatalf("expected group values, got %v", meta)
+	}
+	if _, ok := meta[DefaultSourceSlogKey]; !ok {
+		t.Fatalf("expected a source attr, got %v", meta)
+	}
+	m := AttrMap(err)
+	if m["db.table"] != "users" || m["db.id"] != int64(1) || m["http.table"] != "routes" {
+		t.Fatalf("unexpected attrs %v", m)
+	}
+	if WrapAttrGroup(nil, "db") != nil {
+		t.Fatal("expected nil error")
+	}
+}
+
+func TestOnDuplicateKey(t *testing.T) {
+	defer func(p AttrPrecedence) { DefaultAttrPrecedence = p }(DefaultAttrPrecedence)
+	defer func() { OnDuplicateKey = nil }()
+
+	type collision struct{ key, old, new string }
+	var collisions []collision
+	OnDuplicateKey = func(key string, old, new slog.Value) {
+		collisions = append(collisions, collision{key, old.String(), new.String()})
+	}
+
+	inner := WrapAttr(io.EOF, slog.String("table", "users"), slog.String("id", "1"))
+	err := WrapAttr(Wrap(inner), slog.String("table", "orders"))
+	for _, precedence := range []AttrPrecedence{InnermostAttr, OutermostAttr} {
+		DefaultAttrPrecedence = precedence
+		collisions = nil
+		UnwrapAttr(err)
+		if !slices.Equal(collisions, []collision{{"table", "orders", "users"}}) {
+			t.Fatalf("precedence %d: unexpected collisions %v", precedence, collisions)
+		}
+	}
+
+	collisions = nil
+	UnwrapAttr(WrapAttr(io.EOF, slog.String("table", "users"), slog.String("id", "1")))
+	if len(collisions) != 0 {
+		t.Fatalf("unexpected collisions %v", collisions)
+	}
+}
+
+func TestAttrsString(t *testing.T) {
+	defer func(key string) { DefaultSourceSlogKey = key }(DefaultSourceSlogKey)
+	DefaultSourceSlogKey = ""
+
+	err := WrapAttr(Wrap(WrapAttr(io.EOF, slog.String("table", "users"))), slog.Int("id", 1), slog.Group("db", slog.Bool("primary", true)))
+	if got := AttrsString(err); got != "db=[primary=true] id=1 table=users" {
+		t.Fatalf("unexpected AttrsString %q", got)
+	}
+	if got := AttrsString(io.EOF); got != "" {
+		t.Fatalf("unexpected AttrsString %q", got)
+	}
+}
+
+func TestCtxReason(t *testing.T) {
+	timedOut, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-timedOut.Done()
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"deadline", WrapCtxErr(timedOut.Err()), "deadline"},
+		{"canceled", WrapCtxErr(Errorf("query: %w", cancelled.Err()), slog.Int("id", 1)), "canceled"},
+		{"WrapAttrCtx deadline", WrapAttrCtx(timedOut, timedOut.Err()), "deadline"},
+		{"WrapAttrCtx canceled", WrapAttrCtx(cancelled, WrapAttrCtx(cancelled, cancelled.Err())), "canceled"},
+		{"other", WrapCtxErr(io.EOF), ""},
+	}
+	for _, tt := range tests {
+		var collisions int
+		OnDuplicateKey = func(string, slog.Value, slog.Value) { collisions++ }
+		meta := UnwrapAttr(tt.err)
+		OnDuplicateKey = nil
+		got, ok := meta[DefaultCtxReasonSlogKey]
+		if ok != (tt.want != "") || (ok && got.String() != tt.want) {
+			t.Fatalf("%s: unexpected %s %v", tt.name, DefaultCtxReasonSlogKey, got)
+		}
+		if collisions != 0 {
+			t.Fatalf("%s: expected the reason to be added once", tt.name)
+		}
+	}
+	if WrapCtxErr(nil) != nil {
+		t.Fatal("expected nil error")
+	}
+
+	// Wrapping checks the chain for the reason without reading every attr, so OnDuplicateKey isn't called.
+	dup := WrapAttr(WrapAttr(cancelled.Err(), slog.Int("id", 1)), slog.Int("id", 2))
+	var calls int
+	OnDuplicateKey = func(string, slog.Value, slog.Value) { calls++ }
+	err := WrapCtxErr(dup)
+	OnDuplicateKey = nil
+	if calls != 0 || AttrMap(err)[DefaultCtxReasonSlogKey] != "canceled" {
+		t.Fatalf("unexpected %d OnDuplicateKey calls for %v", calls, AttrMap(err))
+	}
+
+	// A reason within any branch of a joined error is found too.
+	joined := Join(io.EOF, WrapCtxErr(cancelled.Err()))
+	if got := len(ctxReasonAttr(WrapAttr(joined))); got != 0 {
+		t.Fatalf("expected the reason within the joined error to be found, got %d attrs", got)
+	}
+}
+
+// wrapForCaller is a library helper wrapping errors on behalf of its caller.
+func wrapForCaller(ctx context.Context, err error) error {
+	if ctx != nil {
+		return WrapAttrCtxWithSkip(ctx, err, 3, slog.Bool("helper", true))
+	}
+	return WrapAttrWithSkip(err, 3, slog.Bool("helper", true))
+}
+
+func TestWrapAttrWithSkip(t *testing.T) {
+	ctx := AddAttrToCtx(context.Background(), slog.String("user", "bob"))
+	for _, ctx := range []context.Context{nil, ctx} {
+		_, file, line, _ := runtime.Caller(0)
+		err := wrapForCaller(ctx, io.EOF)
+		if !strings.HasPrefix(err.Error(), "errors.TestWrapAttrWithSkip ") {
+			t.Fatalf("expected the caller's name, got %q", err.Error())
+		}
+		meta := UnwrapAttr(err)
+		if want := fmt.Sprintf("%s:%d", file, line+1); meta[DefaultSourceSlogKey].String() != want {
+			t.Fatalf("expected source %s, got %v", want, meta[DefaultSourceSlogKey])
+		}
+		if !meta["helper"].Bool() || (ctx != nil && meta["user"].String() != "bob") {
+			t.Fatalf("unexpected attrs %v", meta)
+		}
+	}
+	if WrapAttrWithSkip(nil, 2) != nil {
+		t.Fatal("expected nil error")
+	}
+}
+
+func TestDefaultFuncSlogKey(t *testing.T) {
+	defer func(key string) { DefaultFuncSlogKey = key }(DefaultFuncSlogKey)
+
+	if _, ok := UnwrapAttr(WrapAttr(io.EOF))["func"]; ok {
+		t.Fatal("expected no func attr by default")
+	}
+
+	DefaultFuncSlogKey = "func"
+	origin := func() error { return WrapAttr(io.EOF, slog.Int("id", 1)) }
+	err := WrapAttr(Wrap(origin()), slog.String("table", "users"))
+	meta := UnwrapAttr(err)
+	if got := meta["func"].String(); got != "errors.TestDefaultFuncSlogKey.func2" {
+		t.Fatalf("expected the originating func, got %q", got)
+	}
+	if !strings.HasPrefix(err.Error(), "errors.TestDefaultFuncSlogKey ") {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	if got := UnwrapAttr(NewGroup("failed", "db"))["func"].String(); got != "errors.TestDefaultFuncSlogKey" {
+		t.Fatalf("unexpected NewGroup func %q", got)
+	}
+}
+
+func TestAttrErrorGoString(t *testing.T) {
+	defer func(key string) { DefaultSourceSlogKey = key }(DefaultSourceSlogKey)
+	DefaultSourceSlogKey = ""
+
+	err := WrapAttr(New("msg"), slog.String("k", "v"), slog.Int("n", 1), slog.Group("g", slog.Bool("ok", true)))
+	want := `errors.WrapAttr(errors.New("errors.TestAttrErrorGoString msg"), slog.String("k", "v"), slog.Int64("n", 1), slog.Group("g", slog.Bool("ok", true)))`
+	if got := fmt.Sprintf("%#v", err); got != want {
+		t.Fatalf("unexpected GoString\n got %s\nwant %s", got, want)
+	}
+
+	err = WrapAttr(err, slog.Duration("d", time.Second))
+	want = `errors.WrapAttr(` + want + `, slog.Duration("d", 1000000000))`
+	if got := fmt.Sprintf("%#v", err); got != want {
+		t.Fatalf("unexpected nested GoString\n got %s\nwant %s", got, want)
+	}
+
+	err = Join(io.EOF, WrapAttr(io.ErrUnexpectedEOF, slog.String("k", "v")))
+	want = `errors.WrapAttr(errors.Join(errors.New("EOF"), errors.WrapAttr(errors.New("unexpected EOF"), slog.String("k", "v"))))`
+	if got := fmt.Sprintf("%#v", err); got != want {
+		t.Fatalf("unexpected Join GoString\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestAppendAttr(t *testing.T) {
+	if AppendAttr(nil, slog.Int("id", 1)) != nil {
+		t.Fatal("expected nil")
+	}
+
+	base := WrapAttr(io.EOF, slog.Int("id", 1))
+	err := AppendAttr(base, slog.String("table", "users"), slog.Int("id", 2))
+	if err.Error() != base.Error() {
+		t.Fatalf("expected the message to be unchanged, got %q", err.Error())
+	}
+	if Unwrap(Unwrap(err)) != io.EOF {
+		t.Fatalf("expected no new layer within %v", err)
+	}
+	if m := AttrMap(err); m["table"] != "users" || m["id"] != int64(2) {
+		t.Fatalf("unexpected attrs %v", m)
+	}
+	if m := AttrMap(base); m["table"] != nil || m["id"] != int64(1) {
+		t.Fatalf("expected the original to be unchanged, got %v", m)
+	}
+
+	// Appending past the attrs a slog.Record stores inline must not share its backing array either.
+	for i := range 10 {
+		base = AppendAttr(base, slog.Int(fmt.Sprint("k", i), i))
+	}
+	a, b := AppendAttr(base, slog.String("branch", "a")), AppendAttr(base, slog.String("branch", "b"))
+	if AttrMap(a)["branch"] != "a" || AttrMap(b)["branch"] != "b" || AttrMap(base)["branch"] != nil {
+		t.Fatalf("unexpected branches %v %v", AttrMap(a), AttrMap(b))
+	}
+	if !Is(a, io.EOF) || !Is(a, b) {
+		t.Fatalf("expected %v to match io.EOF and %v", a, b)
+	}
+
+	defer func(key string) { DefaultSourceSlogKey = key }(DefaultSourceSlogKey)
+	DefaultSourceSlogKey = "source"
+	err = AppendAttr(io.EOF, slog.Int("id", 1))
+	if err.Error() != "errors.TestAppendAttr EOF" || !Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF to be wrapped like WrapAttr, got %q", err.Error())
+	}
+	if source := AttrMap(err)["source"]; source == nil || !strings.Contains(source.(string), "attr_test.go") {
+		t.Fatalf("expected the caller's source, got %v", source)
+	}
+
+	DefaultSourceSlogKey = ""
+	err = AppendAttr(fmt.Errorf("x: %w", io.EOF), slog.Int("id", 1))
+	want := `errors.WrapAttr(errors.New("x: EOF"), slog.Int64("id", 1))`
+	if got := fmt.Sprintf("%#v", err); got != want {
+		t.Fatalf("unexpected GoString\n got %s\nwant %s", got, want)
+	}
+}
+
+func BenchmarkAppendAttr(b *testing.B) {
+	for _, bench := range []struct {
+		name string
+		fn   func(error, ...slog.Attr) error
+	}{{"WrapAttr", WrapAttr}, {"AppendAttr", AppendAttr}} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				err := WrapAttr(io.EOF)
+				for i := range 5 {
+					err = bench.fn(err, slog.Int("i", i))
+				}
+				_ = UnwrapAttr(err)
+			}
+		})
+	}
+}
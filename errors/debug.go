@@ -0,0 +1,6 @@
+//go:build debug
+
+package errors
+
+// debugBuild enables extra diagnostics when built with -tags debug.
+const debugBuild = true
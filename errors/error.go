@@ -9,8 +9,19 @@ import (
 	"fmt"
 	"path"
 	"runtime"
+	"slices"
 )
 
+// DefaultFunctionTrimFunc trims the full name of the calling function, such as github.com/danlock/pkg/errors.New, before it's prepended to errors.
+// By default it keeps the package and function names, such as errors.New.
+// Override it to trim more aggressively, such as dropping the package from closures named like pkg.Outer.func1.1.
+var DefaultFunctionTrimFunc = func(name string) string {
+	// With just the package name and the func name, nested errors look more readable by default.
+	// We also avoid the ugly giant stack trace cluttering logs and looking similar to panics.
+	_, fName := path.Split(name)
+	return fName
+}
+
 // New creates a new error with the package.func of it's caller prepended.
 func New(text string) error {
 	return errors.New(prependCaller(text, 2))
@@ -37,20 +48,43 @@ func Wrap(err error) error {
 	return fmt.Errorf(prependCaller("%w", 2), err)
 }
 
+// WrapfMulti is like Errorf with every one of errs wrapped by a %w appended to format, such as "pkg.func msg: err1: err2".
+// Is, As and UnwrapAttr find each of errs, like an error made by fmt.Errorf with multiple %w.
+// nil errors are skipped, and like Wrap it returns nil if every error is nil.
+func WrapfMulti(errs []error, format string, a ...any) error {
+	var causes []any
+	for _, err := range errs {
+		if err != nil {
+			format += ": %w"
+			causes = append(causes, err)
+		}
+	}
+	if len(causes) == 0 {
+		return nil
+	}
+	return fmt.Errorf(prependCaller(format, 2), append(slices.Clip(a), causes...)...)
+}
+
 func prependCaller(text string, skip int) string {
-	pc, _, _, ok := runtime.Caller(skip)
+	fn, _, _, ok := caller(skip + 1)
 	if !ok {
 		return ""
 	}
+	return fmt.Sprint(fn, " ", text)
+}
+
+// caller returns the package.func trimmed by DefaultFunctionTrimFunc and the file:line of a caller.
+// skip is passed to runtime.Caller, so 1 is the function calling caller.
+func caller(skip int) (fn, file string, line int, ok bool) {
+	pc, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return "", "", 0, false
+	}
 	f := runtime.FuncForPC(pc)
 	if f == nil {
-		return ""
+		return "", "", 0, false
 	}
-	// f.Name() gives back something like github.com/danlock/pkg.funcName.
-	// with just the package name and the func name, nested errors look more readable by default.
-	// We also avoid the ugly giant stack trace cluttering logs and looking similar to panics.
-	_, fName := path.Split(f.Name())
-	return fmt.Sprint(fName, " ", text)
+	return DefaultFunctionTrimFunc(f.Name()), file, line, true
 }
 
 // The following simply call the stdlib so users don't need to include both errors packages.
@@ -65,8 +99,17 @@ func Is(err error, target error) bool {
 	return errors.Is(err, target)
 }
 
+// Join is errors.Join, but the result implements slog.LogValuer like WrapAttr, so the attrs of every branch are logged.
+// It also attaches the caller's file:line under DefaultSourceSlogKey and package.func under DefaultFuncSlogKey if set,
+// since wrapping the result wouldn't when any of errs already has attrs. These take precedence over the same keys within errs,
+// so logs show where the errors were joined.
+// Is and As still match any of errs, and Unwrap on the result returns the errors.Join error with the []error.
 func Join(errs ...error) error {
-	return errors.Join(errs...)
+	err := errors.Join(errs...)
+	if err == nil {
+		return nil
+	}
+	return joinedAttrError(err, originAttrs(caller(2)))
 }
 
 func Unwrap(err error) error {
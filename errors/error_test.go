@@ -0,0 +1,51 @@
+package errors
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestDefaultFunctionTrimFunc(t *testing.T) {
+	defer func(trim func(string) string) { DefaultFunctionTrimFunc = trim }(DefaultFunctionTrimFunc)
+
+	nested := func() error {
+		return func() error { return New("failed") }()
+	}
+	if err := nested(); err.Error() != "errors.TestDefaultFunctionTrimFunc.func2.func1 failed" {
+		t.Fatalf("unexpected default name %q", err.Error())
+	}
+
+	// Drop the closures, keeping the function they're within.
+	defaultTrim := DefaultFunctionTrimFunc
+	DefaultFunctionTrimFunc = func(name string) string {
+		name, _, _ = strings.Cut(defaultTrim(name), ".func")
+		return name
+	}
+	if err := nested(); err.Error() != "errors.TestDefaultFunctionTrimFunc failed" {
+		t.Fatalf("unexpected trimmed name %q", err.Error())
+	}
+	if err := Wrap(New("failed")); err.Error() != "errors.TestDefaultFunctionTrimFunc errors.TestDefaultFunctionTrimFunc failed" {
+		t.Fatalf("unexpected trimmed name %q", err.Error())
+	}
+}
+
+func TestWrapfMulti(t *testing.T) {
+	dbErr := WrapAttr(io.EOF, slog.String("table", "users"))
+	cacheErr := WrapAttr(io.ErrUnexpectedEOF, slog.String("cache", "redis"))
+	err := WrapfMulti([]error{dbErr, nil, cacheErr}, "loading user %d", 7)
+	if want := "errors.TestWrapfMulti loading user 7: " + dbErr.Error() + ": " + cacheErr.Error(); err.Error() != want {
+		t.Fatalf("unexpected message %q, expected %q", err.Error(), want)
+	}
+	if !Is(err, io.EOF) || !Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected %v to match both causes", err)
+	}
+	meta := UnwrapAttr(err)
+	if meta["table"].String() != "users" || meta["cache"].String() != "redis" {
+		t.Fatalf("expected attrs from both causes, got %v", meta)
+	}
+	if WrapfMulti([]error{nil}, "nothing") != nil {
+		t.Fatal("expected nil error")
+	}
+}
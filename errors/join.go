@@ -0,0 +1,49 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// JoinIndexed is like Join, but prefixes the attrs of each branch with its position, such as "branch0." and "branch1.".
+// When concurrent operations fail with the same attr keys, like two queries both setting "table", every value survives in the logs.
+// The position is the index within errs, including nil errors. Like Join, it attaches the caller's origin attrs and returns nil if every error is nil.
+func JoinIndexed(errs ...error) error {
+	branches := make([]error, 0, len(errs))
+	for i, err := range errs {
+		if err != nil {
+			branches = append(branches, indexedBranch{error: err, prefix: fmt.Sprintf("branch%d.", i)})
+		}
+	}
+	if len(branches) == 0 {
+		return nil
+	}
+	return joinedAttrError(errors.Join(branches...), originAttrs(caller(2)))
+}
+
+// joinedAttrError wraps a joined error with the origin attrs of Join, which win over the same keys within its branches.
+func joinedAttrError(err error, origin []slog.Attr) attrError {
+	var r slog.Record
+	r.AddAttrs(origin...)
+	keys := make([]string, len(origin))
+	for i, a := range origin {
+		keys[i] = a.Key
+	}
+	return attrError{error: err, record: r, origin: keys}
+}
+
+// indexedBranch prefixes the keys of every attr within its chain.
+type indexedBranch struct {
+	error
+	prefix string
+}
+
+func (b indexedBranch) Unwrap() error { return b.error }
+
+// updateAttrMap adds the attrs within the branch to meta, with their keys prefixed.
+func (b indexedBranch) updateAttrMap(meta map[string]slog.Value) {
+	branchMeta := make(map[string]slog.Value)
+	updateAttrMapFromErr(b.error, branchMeta)
+	mergeAttrMap(meta, branchMeta, b.prefix)
+}
@@ -0,0 +1,125 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"testing"
+)
+
+func TestJoinIndexed(t *testing.T) {
+	err := JoinIndexed(
+		WrapAttr(io.EOF, slog.String("table", "users")),
+		nil,
+		WrapAttr(io.ErrUnexpectedEOF, slog.String("table", "orders")),
+	)
+	if !Is(err, io.EOF) || !Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected %v to match both branches", err)
+	}
+
+	meta := UnwrapAttr(err)
+	if meta["branch0.table"].String() != "users" || meta["branch2.table"].String() != "orders" {
+		t.Fatalf("unexpected attrs %v", meta)
+	}
+	if _, ok := meta["table"]; ok {
+		t.Fatalf("unexpected unprefixed attr in %v", meta)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "err", err)
+	var logged struct{ Err map[string]any }
+	if err := json.Unmarshal(buf.Bytes(), &logged); err != nil {
+		t.Fatal(err)
+	}
+	if logged.Err["branch0.table"] != "users" || logged.Err["branch2.table"] != "orders" {
+		t.Fatalf("unexpected log %s", buf.String())
+	}
+
+	if JoinIndexed(nil, nil) != nil {
+		t.Fatal("expected nil")
+	}
+}
+
+func TestJoinSource(t *testing.T) {
+	_, file, line, _ := runtime.Caller(0)
+	joined := Join(io.EOF, nil, io.ErrUnexpectedEOF)
+	want := fmt.Sprintf("%s:%d", file, line+1)
+	if !Is(joined, io.EOF) || !Is(joined, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected %v to match both errors", joined)
+	}
+	if got := UnwrapAttr(joined)[DefaultSourceSlogKey].String(); got != want {
+		t.Fatalf("expected source %s, got %s", want, got)
+	}
+
+	// Wrapping a Join whose branches already have attrs keeps the Join's source, without colliding with the branch's.
+	var collisions []string
+	OnDuplicateKey = func(key string, _, _ slog.Value) { collisions = append(collisions, key) }
+	defer func() { OnDuplicateKey = nil }()
+	branch := WrapAttr(io.EOF, slog.String("table", "users"))
+	_, file, line, _ = runtime.Caller(0)
+	err := WrapAttr(Join(branch, io.ErrUnexpectedEOF), slog.Int("id", 1))
+	want = fmt.Sprintf("%s:%d", file, line+1)
+	if meta := UnwrapAttr(err); meta[DefaultSourceSlogKey].String() != want || meta["table"].String() != "users" {
+		t.Fatalf("expected the Join's source %s and the branch's table, got %v", want, meta)
+	}
+	if len(collisions) != 0 {
+		t.Fatalf("unexpected OnDuplicateKey calls for %v", collisions)
+	}
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "err", err)
+	var logged struct{ Err map[string]any }
+	if err := json.Unmarshal(buf.Bytes(), &logged); err != nil {
+		t.Fatal(err)
+	}
+	if logged.Err[DefaultSourceSlogKey] != want {
+		t.Fatalf("expected the Join's source %s in %s", want, buf.String())
+	}
+
+	if Join(nil, nil) != nil {
+		t.Fatal("expected nil error")
+	}
+
+	// JoinIndexed attaches the same origin attrs as Join, including the func when DefaultFuncSlogKey is set.
+	defer func(key string) { DefaultFuncSlogKey = key }(DefaultFuncSlogKey)
+	DefaultFuncSlogKey = "func"
+	_, file, line, _ = runtime.Caller(0)
+	joined, indexed := Join(io.EOF), JoinIndexed(WrapAttr(io.EOF, slog.Int("id", 1)))
+	want = fmt.Sprintf("%s:%d", file, line+1)
+	for _, err := range []error{joined, indexed} {
+		meta := UnwrapAttr(err)
+		if meta[DefaultSourceSlogKey].String() != want || meta["func"].String() != "errors.TestJoinSource" {
+			t.Fatalf("expected source %s and func errors.TestJoinSource, got %v", want, meta)
+		}
+	}
+}
+
+func TestJoinLogValue(t *testing.T) {
+	defer func(key string) { DefaultSourceSlogKey = key }(DefaultSourceSlogKey)
+	DefaultSourceSlogKey = ""
+
+	errTable := WrapAttr(io.EOF, slog.String("table", "users"))
+	joined := Join(errTable, io.ErrUnexpectedEOF)
+	if _, ok := joined.(slog.LogValuer); !ok {
+		t.Fatalf("expected %T to implement slog.LogValuer", joined)
+	}
+	if !Is(joined, errTable) || !Is(joined, io.EOF) || !Is(joined, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected %v to match every branch", joined)
+	}
+	var multi interface{ Unwrap() []error }
+	if !As(joined, &multi) || len(multi.Unwrap()) != 2 {
+		t.Fatalf("expected As to find the joined errors in %v", joined)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "err", joined)
+	var logged struct{ Err map[string]any }
+	if err := json.Unmarshal(buf.Bytes(), &logged); err != nil {
+		t.Fatal(err)
+	}
+	if logged.Err["table"] != "users" || logged.Err[DefaultMsgSlogKey] != joined.Error() {
+		t.Fatalf("unexpected log %s", buf.String())
+	}
+}
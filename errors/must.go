@@ -0,0 +1,81 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+)
+
+// MustPanic is the value Must and MustMsg panic with, recording where the error was found along with the value and error.
+// It implements error and unwraps to Err, so FromPanic turns it into an error that still matches Err with Is and As.
+type MustPanic[T any] struct {
+	Value T
+	Err   error
+	// Msg is the message given to MustMsg, or empty for Must.
+	Msg  string
+	File string
+	Line int
+}
+
+func (p MustPanic[T]) Error() string {
+	if p.Msg == "" {
+		return fmt.Sprintf("%s:%d: %v", p.File, p.Line, p.Err)
+	}
+	return fmt.Sprintf("%s:%d: %s: %v", p.File, p.Line, p.Msg, p.Err)
+}
+
+func (p MustPanic[T]) Unwrap() error { return p.Err }
+
+// Must returns val, or panics with a MustPanic if err isn't nil. Useful for initializing package variables and tests.
+func Must[T any](val T, err error) T {
+	if err != nil {
+		panic(newMustPanic(val, err, ""))
+	}
+	return val
+}
+
+// MustMsg is Must, but the MustPanic also has msg, describing what failed.
+func MustMsg[T any](val T, err error, msg string) T {
+	if err != nil {
+		panic(newMustPanic(val, err, msg))
+	}
+	return val
+}
+
+// MustErr is Must, but panics with err itself for callers that recover and expect the original error.
+func MustErr[T any](val T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// newMustPanic creates a MustPanic with the file:line of the caller of Must or MustMsg.
+func newMustPanic[T any](val T, err error, msg string) MustPanic[T] {
+	_, file, line, _ := runtime.Caller(2)
+	return MustPanic[T]{Value: val, Err: err, Msg: msg, File: file, Line: line}
+}
+
+// osExit is os.Exit, replaceable by tests.
+var osExit = os.Exit
+
+// MustLog returns a Must that logs the error at Error level with logger and exits the process with status 1, instead of panicking.
+// It's for startup paths in a main package that should exit cleanly without a stack dump.
+// slog.Default() is used if logger is nil. The error is logged with its attrs, since it's a slog.LogValuer when wrapped by this package.
+// Deferred functions don't run, since the process exits through os.Exit.
+func MustLog[T any](logger *slog.Logger) func(T, error) T {
+	return func(val T, err error) T {
+		if err != nil {
+			logger := logger
+			if logger == nil {
+				logger = slog.Default()
+			}
+			_, file, line, _ := runtime.Caller(1)
+			logger.LogAttrs(context.Background(), slog.LevelError, "exiting due to error", slog.Any("err", err), slog.String("caller", fmt.Sprintf("%s:%d", file, line)))
+			osExit(1)
+		}
+		return val
+	}
+}
@@ -0,0 +1,82 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"testing"
+)
+
+func TestMust(t *testing.T) {
+	if got := Must(1, nil); got != 1 {
+		t.Fatalf("unexpected Must %d", got)
+	}
+	if got := MustMsg("a", nil, "reading"); got != "a" {
+		t.Fatalf("unexpected MustMsg %q", got)
+	}
+	if got := MustErr(2, nil); got != 2 {
+		t.Fatalf("unexpected MustErr %d", got)
+	}
+
+	_, file, line, _ := runtime.Caller(0)
+	err := recoverFrom(func() { Must(3, io.EOF) })
+	var p MustPanic[int]
+	if !As(err, &p) || !Is(err, io.EOF) {
+		t.Fatalf("expected a MustPanic wrapping io.EOF, got %v", err)
+	}
+	if p.Value != 3 || p.Err != io.EOF || p.File != file || p.Line != line+1 || p.Msg != "" {
+		t.Fatalf("unexpected MustPanic %+v", p)
+	}
+	if want := fmt.Sprintf("%s:%d: EOF", file, line+1); p.Error() != want {
+		t.Fatalf("unexpected message %q, expected %q", p.Error(), want)
+	}
+
+	_, _, line, _ = runtime.Caller(0)
+	err = recoverFrom(func() { MustMsg("partial", io.ErrUnexpectedEOF, "reading config") })
+	var msgPanic MustPanic[string]
+	if !As(err, &msgPanic) || msgPanic.Value != "partial" || msgPanic.Msg != "reading config" {
+		t.Fatalf("unexpected MustMsg panic %v", err)
+	}
+	if want := fmt.Sprintf("%s:%d: reading config: unexpected EOF", file, line+1); msgPanic.Error() != want {
+		t.Fatalf("unexpected message %q, expected %q", msgPanic.Error(), want)
+	}
+
+	var recovered any
+	func() {
+		defer func() { recovered = recover() }()
+		MustErr(4, io.EOF)
+	}()
+	if recovered != io.EOF {
+		t.Fatalf("expected MustErr to panic with the error itself, got %v", recovered)
+	}
+}
+
+func TestMustLog(t *testing.T) {
+	defer func(exit func(int)) { osExit = exit }(osExit)
+	var code int
+	osExit = func(c int) { code = c }
+
+	var buf bytes.Buffer
+	must := MustLog[int](slog.New(slog.NewJSONHandler(&buf, nil)))
+	if got := must(1, nil); got != 1 || code != 0 || buf.Len() != 0 {
+		t.Fatalf("unexpected MustLog %d exit %d log %s", got, code, buf.String())
+	}
+
+	must(0, WrapAttr(io.EOF, slog.String("config", "app.toml")))
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	var logged struct {
+		Level string
+		Err   map[string]any
+	}
+	if err := json.Unmarshal(buf.Bytes(), &logged); err != nil {
+		t.Fatal(err)
+	}
+	if logged.Level != "ERROR" || logged.Err["config"] != "app.toml" {
+		t.Fatalf("unexpected log %s", buf.String())
+	}
+}
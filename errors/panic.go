@@ -0,0 +1,38 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+)
+
+var (
+	// DefaultPanicStackSlogKey is the key FromPanic uses for the stack of the panicking goroutine.
+	DefaultPanicStackSlogKey = "panic_stack"
+	// DefaultPanicTypeSlogKey is the key FromPanic uses for the type of the recovered value.
+	DefaultPanicTypeSlogKey = "panic_type"
+)
+
+// FromPanic converts a value returned by recover() into an error, so every recovered panic logs the same way.
+// An error is wrapped, a string becomes the message, and any other value is formatted with %v.
+// The goroutine's stack and the recovered value's type are attached as attrs.
+// FromPanic returns nil if recovered is nil, so it can be called unconditionally with recover().
+func FromPanic(recovered any) error {
+	var err error
+	switch v := recovered.(type) {
+	case nil:
+		return nil
+	case error:
+		err = fmt.Errorf("panic: %w", v)
+	case string:
+		err = errors.New("panic: " + v)
+	default:
+		err = fmt.Errorf("panic: %v", v)
+	}
+
+	return wrapAttr(err, 3, []slog.Attr{
+		slog.String(DefaultPanicTypeSlogKey, fmt.Sprintf("%T", recovered)),
+		slog.String(DefaultPanicStackSlogKey, string(debug.Stack())),
+	})
+}
@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func recoverFrom(fn func()) (err error) {
+	defer func() { err = FromPanic(recover()) }()
+	fn()
+	return nil
+}
+
+func TestFromPanic(t *testing.T) {
+	tests := []struct {
+		name     string
+		panicVal any
+		wantType string
+		wantMsg  string
+	}{
+		{"error", io.EOF, "*errors.errorString", "panic: EOF"},
+		{"string", "boom", "string", "panic: boom"},
+		{"int", 42, "int", "panic: 42"},
+		{"struct", struct{ A int }{A: 1}, "struct { A int }", "panic: {1}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := recoverFrom(func() { panic(tt.panicVal) })
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.HasSuffix(err.Error(), tt.wantMsg) {
+				t.Fatalf("unexpected message %q", err.Error())
+			}
+			if !strings.HasPrefix(err.Error(), "errors.recoverFrom.func1 ") {
+				t.Fatalf("expected caller prefix, got %q", err.Error())
+			}
+			if origErr, ok := tt.panicVal.(error); ok && !Is(err, origErr) {
+				t.Fatalf("expected %v to wrap %v", err, origErr)
+			}
+
+			meta := UnwrapAttr(err)
+			if got := meta[DefaultPanicTypeSlogKey].String(); got != tt.wantType {
+				t.Fatalf("unexpected panic type %q", got)
+			}
+			if stack := meta[DefaultPanicStackSlogKey].String(); !strings.Contains(stack, "TestFromPanic") {
+				t.Fatalf("expected stack to contain the panicking test, got %s", stack)
+			}
+			if _, ok := meta[DefaultSourceSlogKey]; !ok {
+				t.Fatalf("expected source attr, got %v", meta)
+			}
+		})
+	}
+
+	if err := recoverFrom(func() {}); err != nil {
+		t.Fatalf("expected nil error without a panic, got %v", err)
+	}
+}
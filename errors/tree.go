@@ -0,0 +1,68 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Tree renders the error chain as an indented tree for debugging deeply nested errors in a terminal.
+// Each line is a layer's own message followed by its attrs, with the layer it wraps indented below it.
+// The branches of joined errors are each indented below the join.
+func Tree(err error) string {
+	var b strings.Builder
+	writeTree(&b, err, 0)
+	return b.String()
+}
+
+func writeTree(b *strings.Builder, err error, depth int) {
+	// attrError layers don't add a message, so their attrs are written on the next line.
+	var attrs []slog.Attr
+	for err != nil {
+		if ae, ok := err.(attrError); ok {
+			ae.record.Attrs(func(a slog.Attr) bool {
+				attrs = append(attrs, a)
+				return true
+			})
+		}
+
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			branches := joined.Unwrap()
+			writeTreeLine(b, depth, fmt.Sprintf("joined %d errors", len(branches)), attrs)
+			for _, branch := range branches {
+				writeTree(b, branch, depth+1)
+			}
+			return
+		}
+
+		next := errors.Unwrap(err)
+		msg := err.Error()
+		if next != nil {
+			// Only keep what this layer added, such as "pkg.func" from "pkg.func inner error".
+			msg = strings.TrimRight(strings.TrimSuffix(msg, next.Error()), ": ")
+		}
+		if msg != "" {
+			writeTreeLine(b, depth, msg, attrs)
+			attrs = nil
+			depth++
+		}
+		err = next
+	}
+}
+
+func writeTreeLine(b *strings.Builder, depth int, msg string, attrs []slog.Attr) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(msg)
+	if len(attrs) > 0 {
+		b.WriteString(" {")
+		for i, a := range attrs {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(a.String())
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte('\n')
+}
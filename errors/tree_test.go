@@ -0,0 +1,33 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	defer func(key string) { DefaultSourceSlogKey = key }(DefaultSourceSlogKey)
+	DefaultSourceSlogKey = ""
+
+	users := WrapAttr(io.EOF, slog.String("table", "users"))
+	orders := fmt.Errorf("query failed: %w", WrapAttr(io.ErrUnexpectedEOF, slog.String("table", "orders")))
+	err := WrapAttr(Wrap(Join(users, orders)), slog.Int("id", 1))
+
+	want := `errors.TestTree {id=1}
+  errors.TestTree
+    joined 2 errors
+      errors.TestTree {table=users}
+        EOF
+      query failed
+        errors.TestTree {table=orders}
+          unexpected EOF
+`
+	if got := Tree(err); got != want {
+		t.Fatalf("unexpected tree\n%s\nexpected\n%s", got, want)
+	}
+	if got := Tree(nil); got != "" {
+		t.Fatalf("unexpected tree for nil %q", got)
+	}
+}
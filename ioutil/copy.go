@@ -0,0 +1,37 @@
+package ioutil
+
+import (
+	"context"
+	"io"
+
+	"github.com/danlock/pkg/errors"
+)
+
+// CopyContext is io.Copy, but checks ctx before copying each chunk, returning the bytes copied so far and ctx.Err() once it finishes.
+// A read or write blocked in progress isn't interrupted, so ctx is only noticed between chunks.
+func CopyContext(ctx context.Context, dst io.Writer, src io.Reader) (written int64, err error) {
+	buf := make([]byte, 32<<10)
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, errors.Wrap(err)
+		}
+
+		n, readErr := src.Read(buf)
+		if n > 0 {
+			w, err := dst.Write(buf[:n])
+			written += int64(w)
+			if err != nil {
+				return written, errors.Wrap(err)
+			}
+			if w != n {
+				return written, errors.Wrap(io.ErrShortWrite)
+			}
+		}
+		if readErr == io.EOF {
+			return written, nil
+		}
+		if readErr != nil {
+			return written, errors.Wrap(readErr)
+		}
+	}
+}
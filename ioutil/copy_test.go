@@ -0,0 +1,50 @@
+package ioutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+// chunkReader returns up to size bytes per Read, calling onRead after each.
+type chunkReader struct {
+	r      io.Reader
+	size   int
+	onRead func()
+}
+
+func (c chunkReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p[:min(len(p), c.size)])
+	c.onRead()
+	return n, err
+}
+
+func TestCopyContext(t *testing.T) {
+	var dst bytes.Buffer
+	n, err := CopyContext(context.Background(), &dst, bytes.NewReader(make([]byte, 100<<10)))
+	if err != nil || n != 100<<10 || dst.Len() != 100<<10 {
+		t.Fatalf("unexpected copy %d err %v", n, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reads := 0
+	src := chunkReader{r: bytes.NewReader(make([]byte, 100)), size: 10, onRead: func() {
+		if reads++; reads == 3 {
+			cancel()
+		}
+	}}
+	dst.Reset()
+	n, err = CopyContext(ctx, &dst, src)
+	if !errors.Is(err, context.Canceled) || n != 30 || dst.Len() != 30 {
+		t.Fatalf("unexpected copy %d err %v", n, err)
+	}
+
+	errRead := errors.New("read failed")
+	n, err = CopyContext(context.Background(), io.Discard, io.MultiReader(bytes.NewReader([]byte("abc")), errReader{errRead}))
+	if !errors.Is(err, errRead) || n != 3 {
+		t.Fatalf("unexpected copy %d err %v", n, err)
+	}
+}
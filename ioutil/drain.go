@@ -0,0 +1,24 @@
+package ioutil
+
+import (
+	"io"
+
+	"github.com/danlock/pkg/errors"
+)
+
+// DefaultDrainLimit is the most DrainClose reads before giving up on draining, so a huge body can't keep it busy.
+var DefaultDrainLimit int64 = 256 << 10
+
+// DrainClose discards what's left of rc, up to DefaultDrainLimit bytes, then closes it.
+// Draining an http.Response.Body before closing it lets the connection be reused.
+// Errors from reading and closing are joined.
+func DrainClose(rc io.ReadCloser) error {
+	return DrainCloseLimit(rc, DefaultDrainLimit)
+}
+
+// DrainCloseLimit is DrainClose with a limit of n bytes instead of DefaultDrainLimit, for callers that know how big their bodies get.
+// If n isn't positive, rc is closed without draining it.
+func DrainCloseLimit(rc io.ReadCloser, n int64) error {
+	_, readErr := io.Copy(io.Discard, io.LimitReader(rc, n))
+	return errors.Wrap(errors.Join(readErr, rc.Close()))
+}
@@ -0,0 +1,68 @@
+package ioutil
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+type testReadCloser struct {
+	io.Reader
+	closed   bool
+	closeErr error
+}
+
+func (rc *testReadCloser) Close() error {
+	rc.closed = true
+	return rc.closeErr
+}
+
+func TestDrainClose(t *testing.T) {
+	r := bytes.NewReader(make([]byte, 1000))
+	rc := &testReadCloser{Reader: r}
+	if err := DrainClose(rc); err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != 0 || !rc.closed {
+		t.Fatalf("expected a drained and closed reader, %d bytes left, closed %t", r.Len(), rc.closed)
+	}
+
+	// Draining stops at the limit.
+	r = bytes.NewReader(make([]byte, DefaultDrainLimit+10))
+	rc = &testReadCloser{Reader: r}
+	if err := DrainClose(rc); err != nil || r.Len() != 10 || !rc.closed {
+		t.Fatalf("unexpected err %v, %d bytes left, closed %t", err, r.Len(), rc.closed)
+	}
+
+	errRead, errClose := errors.New("read failed"), errors.New("close failed")
+	rc = &testReadCloser{Reader: io.MultiReader(bytes.NewReader([]byte("abc")), errReader{errRead}), closeErr: errClose}
+	if err := DrainClose(rc); !errors.Is(err, errRead) || !errors.Is(err, errClose) || !rc.closed {
+		t.Fatalf("unexpected err %v closed %t", err, rc.closed)
+	}
+}
+
+func TestDrainCloseLimit(t *testing.T) {
+	r := bytes.NewReader(make([]byte, 100))
+	rc := &testReadCloser{Reader: r}
+	if err := DrainCloseLimit(rc, 60); err != nil || r.Len() != 40 || !rc.closed {
+		t.Fatalf("unexpected err %v, %d bytes left, closed %t", err, r.Len(), rc.closed)
+	}
+
+	// A limit above DefaultDrainLimit drains more than DrainClose would.
+	r = bytes.NewReader(make([]byte, DefaultDrainLimit+10))
+	rc = &testReadCloser{Reader: r}
+	if err := DrainCloseLimit(rc, DefaultDrainLimit+10); err != nil || r.Len() != 0 || !rc.closed {
+		t.Fatalf("unexpected err %v, %d bytes left, closed %t", err, r.Len(), rc.closed)
+	}
+
+	r = bytes.NewReader(make([]byte, 100))
+	rc = &testReadCloser{Reader: r}
+	if err := DrainCloseLimit(rc, 0); err != nil || r.Len() != 100 || !rc.closed {
+		t.Fatalf("unexpected err %v, %d bytes left, closed %t", err, r.Len(), rc.closed)
+	}
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
@@ -0,0 +1,152 @@
+package ioutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrSeekOutOfBuffer is returned by the io.ReadSeeker of PipeReadSeeker when seeking to data that's no longer buffered or not yet written.
+var ErrSeekOutOfBuffer = errors.New("ioutil: seek outside of the buffered pipe data")
+
+// PipeReadSeeker is io.Pipe with a reader that can Seek within the last bufSize bytes written,
+// for protocols that re-read recently received data without keeping the entire stream in memory.
+// Data is kept in a circular buffer of bufSize bytes. Data already read is kept for seeking back to until its space is needed for new writes,
+// and Write blocks while the buffer is full of data that hasn't been read yet.
+// Seeking relative to io.SeekEnd is relative to the data written so far.
+// The reader also implements io.Closer, making later writes fail with io.ErrClosedPipe, so a writer isn't blocked forever on a reader that gave up.
+func PipeReadSeeker(bufSize int) (*PipeWriter, io.ReadSeeker) {
+	p := &pipe{buf: make([]byte, max(bufSize, 1))}
+	p.cond.L = &p.mu
+	return &PipeWriter{p: p}, &pipeReader{p: p}
+}
+
+// PipeWriter is the write half of PipeReadSeeker.
+type PipeWriter struct {
+	p *pipe
+}
+
+// Write writes b into the buffer, blocking while it's full of data that hasn't been read yet.
+func (w *PipeWriter) Write(b []byte) (int, error) {
+	return w.p.write(b)
+}
+
+// Close closes the writer, so reads return io.EOF once the buffered data has been read.
+func (w *PipeWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+// CloseWithError closes the writer, so reads return err once the buffered data has been read, or io.EOF if err is nil.
+func (w *PipeWriter) CloseWithError(err error) error {
+	if err == nil {
+		err = io.EOF
+	}
+	w.p.mu.Lock()
+	defer w.p.mu.Unlock()
+	if w.p.writeErr == nil {
+		w.p.writeErr = err
+	}
+	w.p.cond.Broadcast()
+	return nil
+}
+
+// pipe is the state shared by both halves of PipeReadSeeker. Offsets are absolute within the stream.
+type pipe struct {
+	mu   sync.Mutex
+	cond sync.Cond
+	buf  []byte
+	// start is the offset of the oldest buffered byte, end is the offset after the newest.
+	start, end int64
+	// pos is the reader's offset.
+	pos int64
+	// writeErr is returned by reads after the buffered data once the writer closed.
+	writeErr error
+	// readClosed makes writes fail.
+	readClosed bool
+}
+
+func (p *pipe) write(b []byte) (n int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	size := int64(len(p.buf))
+	for len(b) > 0 {
+		if p.readClosed || p.writeErr != nil {
+			return n, io.ErrClosedPipe
+		}
+		if p.end-p.start == size {
+			// Make room by dropping data that was already read, or wait for the reader to read some.
+			if p.pos == p.start {
+				p.cond.Wait()
+				continue
+			}
+			p.start += min(p.pos-p.start, int64(len(b)))
+		}
+
+		i := p.end % size
+		room := min(size-(p.end-p.start), size-i)
+		copied := copy(p.buf[i:i+room], b)
+		b = b[copied:]
+		n += copied
+		p.end += int64(copied)
+		p.cond.Broadcast()
+	}
+	return n, nil
+}
+
+type pipeReader struct {
+	p *pipe
+}
+
+func (r *pipeReader) Read(b []byte) (int, error) {
+	p := r.p
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(b) == 0 {
+		return 0, nil
+	}
+	for p.pos == p.end {
+		if p.writeErr != nil {
+			return 0, p.writeErr
+		}
+		if p.readClosed {
+			return 0, io.ErrClosedPipe
+		}
+		p.cond.Wait()
+	}
+
+	size := int64(len(p.buf))
+	i := p.pos % size
+	n := copy(b, p.buf[i:min(size, i+p.end-p.pos)])
+	p.pos += int64(n)
+	p.cond.Broadcast()
+	return n, nil
+}
+
+func (r *pipeReader) Seek(offset int64, whence int) (int64, error) {
+	p := r.p
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch whence {
+	case io.SeekCurrent:
+		offset += p.pos
+	case io.SeekEnd:
+		offset += p.end
+	case io.SeekStart:
+	default:
+		return p.pos, fmt.Errorf("ioutil: invalid whence %d", whence)
+	}
+	if offset < p.start || offset > p.end {
+		return p.pos, ErrSeekOutOfBuffer
+	}
+	p.pos = offset
+	return offset, nil
+}
+
+func (r *pipeReader) Close() error {
+	r.p.mu.Lock()
+	defer r.p.mu.Unlock()
+	r.p.readClosed = true
+	r.p.cond.Broadcast()
+	return nil
+}
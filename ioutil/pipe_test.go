@@ -0,0 +1,100 @@
+package ioutil
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestPipeReadSeeker(t *testing.T) {
+	w, r := PipeReadSeeker(8)
+
+	if n, err := w.Write([]byte("hello")); n != 5 || err != nil {
+		t.Fatalf("unexpected write %d %v", n, err)
+	}
+	p := make([]byte, 3)
+	if n, err := r.Read(p); err != nil || string(p[:n]) != "hel" {
+		t.Fatalf("unexpected read %q err %v", p[:n], err)
+	}
+	// Re-read data that was already read.
+	if pos, err := r.Seek(-2, io.SeekCurrent); err != nil || pos != 1 {
+		t.Fatalf("unexpected pos %d err %v", pos, err)
+	}
+	if n, err := r.Read(p); err != nil || string(p[:n]) != "ell" {
+		t.Fatalf("unexpected read %q err %v", p[:n], err)
+	}
+	if _, err := r.Seek(1, io.SeekEnd); !errors.Is(err, ErrSeekOutOfBuffer) {
+		t.Fatalf("expected ErrSeekOutOfBuffer seeking past the written data, got %v", err)
+	}
+
+	// The buffer has room for 3 more bytes, then drops the data already read to make room, wrapping around.
+	if n, err := w.Write([]byte(" world")); n != 6 || err != nil {
+		t.Fatalf("unexpected write %d %v", n, err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); !errors.Is(err, ErrSeekOutOfBuffer) {
+		t.Fatalf("expected ErrSeekOutOfBuffer seeking to dropped data, got %v", err)
+	}
+	if pos, err := r.Seek(0, 42); err == nil || errors.Is(err, ErrSeekOutOfBuffer) || pos != 4 {
+		t.Fatalf("expected an invalid whence error without moving, got %d %v", pos, err)
+	}
+	if pos, err := r.Seek(0, io.SeekCurrent); err != nil || pos != 4 {
+		t.Fatalf("unexpected pos %d err %v", pos, err)
+	}
+
+	// The buffer is full of unread data, so the next write blocks until the reader catches up.
+	written := make(chan error)
+	go func() {
+		_, err := w.Write([]byte("!!"))
+		if err == nil {
+			err = w.Close()
+		}
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		t.Fatalf("expected the write to block, got %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	b, err := io.ReadAll(r)
+	if err != nil || string(b) != "o world!!" {
+		t.Fatalf("unexpected read %q err %v", b, err)
+	}
+	if err := <-written; err != nil {
+		t.Fatal(err)
+	}
+	if pos, err := r.Seek(-3, io.SeekEnd); err != nil || pos != 10 {
+		t.Fatalf("unexpected pos %d err %v", pos, err)
+	}
+	if b, err := io.ReadAll(r); err != nil || string(b) != "d!!" {
+		t.Fatalf("unexpected read %q err %v", b, err)
+	}
+	if _, err := w.Write([]byte("late")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("expected io.ErrClosedPipe, got %v", err)
+	}
+}
+
+func TestPipeReadSeekerClose(t *testing.T) {
+	errBroken := errors.New("broken")
+	w, r := PipeReadSeeker(4)
+	go func() {
+		w.Write([]byte("ab"))
+		w.CloseWithError(errBroken)
+	}()
+	if b, err := io.ReadAll(r); !errors.Is(err, errBroken) || string(b) != "ab" {
+		t.Fatalf("unexpected read %q err %v", b, err)
+	}
+
+	// Closing the reader unblocks a writer waiting for room.
+	w, r = PipeReadSeeker(2)
+	written := make(chan error)
+	go func() {
+		_, err := w.Write([]byte("abc"))
+		written <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	r.(io.Closer).Close()
+	if err := <-written; !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("expected io.ErrClosedPipe, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+// Package ioutil contains small helpers for the io interfaces that the stdlib lacks.
+package ioutil
+
+import "io"
+
+// RepeatReader returns a reader of n repetitions of b, like bytes.Repeat without allocating the entire output.
+// It repeats forever if n is 0 or negative. b must not be modified while the reader is in use.
+func RepeatReader(b []byte, n int) io.Reader {
+	if n <= 0 {
+		n = -1
+	}
+	return &repeatReader{b: b, left: n}
+}
+
+type repeatReader struct {
+	b   []byte
+	pos int
+	// left is the number of repetitions left to read, or -1 to repeat forever.
+	left int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	if len(r.b) == 0 || r.left == 0 {
+		return 0, io.EOF
+	}
+
+	var n int
+	for n < len(p) && r.left != 0 {
+		copied := copy(p[n:], r.b[r.pos:])
+		n += copied
+		r.pos += copied
+		if r.pos == len(r.b) {
+			r.pos = 0
+			if r.left > 0 {
+				r.left--
+			}
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,51 @@
+package ioutil
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestRepeatReader(t *testing.T) {
+	tests := []struct {
+		b    string
+		n    int
+		want string
+	}{
+		{"abc", 1, "abc"},
+		{"abc", 3, "abcabcabc"},
+		{"", 3, ""},
+	}
+	for _, tt := range tests {
+		got, err := io.ReadAll(RepeatReader([]byte(tt.b), tt.n))
+		if err != nil {
+			t.Fatalf("RepeatReader(%q, %d) failed with %v", tt.b, tt.n, err)
+		}
+		if string(got) != tt.want {
+			t.Fatalf("RepeatReader(%q, %d) == %q", tt.b, tt.n, got)
+		}
+	}
+
+	// Reads smaller than b resume where they left off.
+	r := RepeatReader([]byte("abcde"), 2)
+	buf := make([]byte, 3)
+	var got []byte
+	for {
+		n, err := r.Read(buf)
+		got = append(got, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+	}
+	if string(got) != "abcdeabcde" {
+		t.Fatalf("unexpected output %q", got)
+	}
+
+	for _, n := range []int{0, -1} {
+		const size = 1_000_000
+		got, err := io.ReadAll(io.LimitReader(RepeatReader([]byte("xy"), n), size))
+		if err != nil || len(got) != size || !bytes.Equal(got, bytes.Repeat([]byte("xy"), size/2)) {
+			t.Fatalf("RepeatReader(n=%d) read %d bytes err %v", n, len(got), err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package ioutil
+
+import (
+	"errors"
+	"io"
+)
+
+// RetryReader returns a reader that retries a failed Read of r up to retries times in a row while shouldRetry returns true for the error,
+// for transient failures such as a network read ending early. shouldRetry only retries io.ErrUnexpectedEOF if it's nil.
+// Data returned along with a retryable error is returned without the error, so the next Read retries.
+// Once the retries are exhausted, Read returns the last error. io.EOF is never retried.
+func RetryReader(r io.Reader, retries int, shouldRetry func(error) bool) io.Reader {
+	return &retryReader{r: r, retries: retries, shouldRetry: retryShouldRetry(shouldRetry)}
+}
+
+// RetryReadSeeker is RetryReader for an io.ReadSeeker. Seek isn't retried.
+func RetryReadSeeker(r io.ReadSeeker, retries int, shouldRetry func(error) bool) io.ReadSeeker {
+	return &retryReadSeeker{retryReader: retryReader{r: r, retries: retries, shouldRetry: retryShouldRetry(shouldRetry)}, s: r}
+}
+
+func retryShouldRetry(shouldRetry func(error) bool) func(error) bool {
+	if shouldRetry != nil {
+		return shouldRetry
+	}
+	return func(err error) bool { return errors.Is(err, io.ErrUnexpectedEOF) }
+}
+
+type retryReader struct {
+	r           io.Reader
+	retries     int
+	shouldRetry func(error) bool
+}
+
+func (r *retryReader) Read(p []byte) (n int, err error) {
+	for attempt := 0; ; attempt++ {
+		n, err = r.r.Read(p)
+		if err == nil || err == io.EOF || !r.shouldRetry(err) {
+			return n, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+		if attempt >= r.retries {
+			return n, err
+		}
+	}
+}
+
+type retryReadSeeker struct {
+	retryReader
+	s io.Seeker
+}
+
+func (r *retryReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	return r.s.Seek(offset, whence)
+}
@@ -0,0 +1,71 @@
+package ioutil
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// flakyReader fails the reads listed in fails with their error, reading from r otherwise.
+type flakyReader struct {
+	io.ReadSeeker
+	fails []error
+	reads int
+}
+
+func (f *flakyReader) Read(p []byte) (int, error) {
+	f.reads++
+	if len(f.fails) > 0 {
+		err := f.fails[0]
+		f.fails = f.fails[1:]
+		if err != nil {
+			return 0, err
+		}
+	}
+	return f.ReadSeeker.Read(p)
+}
+
+func TestRetryReader(t *testing.T) {
+	f := &flakyReader{ReadSeeker: strings.NewReader("hello"), fails: []error{io.ErrUnexpectedEOF, io.ErrUnexpectedEOF}}
+	if b, err := io.ReadAll(RetryReader(f, 2, nil)); err != nil || string(b) != "hello" {
+		t.Fatalf("unexpected read %q err %v", b, err)
+	}
+
+	f = &flakyReader{ReadSeeker: strings.NewReader("hello"), fails: []error{io.ErrUnexpectedEOF, io.ErrUnexpectedEOF, io.ErrUnexpectedEOF}}
+	if _, err := RetryReader(f, 2, nil).Read(make([]byte, 5)); !errors.Is(err, io.ErrUnexpectedEOF) || f.reads != 3 {
+		t.Fatalf("expected the last error after exhausting retries, got %v after %d reads", err, f.reads)
+	}
+
+	errOther := errors.New("connection reset")
+	f = &flakyReader{ReadSeeker: strings.NewReader("hello"), fails: []error{errOther}}
+	if _, err := RetryReader(f, 5, nil).Read(make([]byte, 5)); !errors.Is(err, errOther) || f.reads != 1 {
+		t.Fatalf("expected no retries for other errors, got %v after %d reads", err, f.reads)
+	}
+	f = &flakyReader{ReadSeeker: strings.NewReader("hello"), fails: []error{errOther, errOther}}
+	r := RetryReader(f, 5, func(err error) bool { return errors.Is(err, errOther) })
+	if b, err := io.ReadAll(r); err != nil || string(b) != "hello" {
+		t.Fatalf("unexpected read %q err %v", b, err)
+	}
+
+	// Retries reset after a successful read.
+	f = &flakyReader{ReadSeeker: strings.NewReader("hello"), fails: []error{io.ErrUnexpectedEOF, nil, io.ErrUnexpectedEOF}}
+	r = RetryReader(f, 1, nil)
+	p := make([]byte, 2)
+	for _, want := range []string{"he", "ll"} {
+		if n, err := r.Read(p); err != nil || string(p[:n]) != want {
+			t.Fatalf("unexpected read %q err %v", p[:n], err)
+		}
+	}
+}
+
+func TestRetryReadSeeker(t *testing.T) {
+	f := &flakyReader{ReadSeeker: strings.NewReader("hello"), fails: []error{io.ErrUnexpectedEOF}}
+	rs := RetryReadSeeker(f, 1, nil)
+	if pos, err := rs.Seek(1, io.SeekStart); err != nil || pos != 1 {
+		t.Fatalf("unexpected pos %d err %v", pos, err)
+	}
+	if b, err := io.ReadAll(rs); err != nil || string(b) != "ello" {
+		t.Fatalf("unexpected read %q err %v", b, err)
+	}
+}
@@ -0,0 +1,60 @@
+package ioutil
+
+import (
+	"io"
+
+	"github.com/danlock/pkg/errors"
+)
+
+// SectionReadSeeker returns an io.ReadSeeker over the length bytes of r starting at offset, like io.SectionReader
+// but for an io.ReadSeeker instead of an io.ReaderAt. r is seeked to offset first, and positions are relative to the section,
+// so Seek(0, io.SeekStart) seeks r back to offset. Reads stop with io.EOF at the end of the section.
+// The section shares r's position, so r shouldn't be used directly while the returned io.ReadSeeker is.
+func SectionReadSeeker(r io.ReadSeeker, offset, length int64) (io.ReadSeeker, error) {
+	if offset < 0 || length < 0 {
+		return nil, errors.Errorf("negative offset %d or length %d", offset, length)
+	}
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return &sectionReadSeeker{r: r, offset: offset, length: length}, nil
+}
+
+type sectionReadSeeker struct {
+	r      io.ReadSeeker
+	offset int64
+	length int64
+	pos    int64
+}
+
+func (s *sectionReadSeeker) Read(p []byte) (int, error) {
+	if s.pos >= s.length {
+		return 0, io.EOF
+	}
+	if remaining := s.length - s.pos; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := s.r.Read(p)
+	s.pos += int64(n)
+	return n, err
+}
+
+func (s *sectionReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.pos
+	case io.SeekEnd:
+		offset += s.length
+	default:
+		return 0, errors.Errorf("invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, errors.Errorf("negative position %d", offset)
+	}
+	if _, err := s.r.Seek(s.offset+offset, io.SeekStart); err != nil {
+		return s.pos, errors.Wrap(err)
+	}
+	s.pos = offset
+	return s.pos, nil
+}
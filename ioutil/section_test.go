@@ -0,0 +1,44 @@
+package ioutil
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSectionReadSeeker(t *testing.T) {
+	rs, err := SectionReadSeeker(strings.NewReader("hello wide world"), 6, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := io.ReadAll(rs); err != nil || string(b) != "wide" {
+		t.Fatalf("unexpected section %q %v", b, err)
+	}
+
+	seekAndRead := func(offset int64, whence int, wantPos int64, n int, want string) {
+		t.Helper()
+		pos, err := rs.Seek(offset, whence)
+		if err != nil || pos != wantPos {
+			t.Fatalf("Seek(%d, %d) == %d, %v", offset, whence, pos, err)
+		}
+		b := make([]byte, n)
+		got, _ := io.ReadFull(rs, b)
+		if string(b[:got]) != want {
+			t.Fatalf("expected %q after Seek(%d, %d), got %q", want, offset, whence, b[:got])
+		}
+	}
+	seekAndRead(0, io.SeekStart, 0, 2, "wi")
+	seekAndRead(1, io.SeekCurrent, 3, 5, "e")
+	seekAndRead(-3, io.SeekEnd, 1, 10, "ide")
+	seekAndRead(10, io.SeekStart, 10, 1, "")
+
+	if _, err := rs.Seek(-1, io.SeekStart); err == nil {
+		t.Fatal("expected an error seeking before the section")
+	}
+	if _, err := rs.Seek(0, 42); err == nil {
+		t.Fatal("expected an error for an invalid whence")
+	}
+	if _, err := SectionReadSeeker(strings.NewReader(""), -1, 1); err == nil {
+		t.Fatal("expected an error for a negative offset")
+	}
+}
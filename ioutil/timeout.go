@@ -0,0 +1,144 @@
+package ioutil
+
+import (
+	"io"
+	"os"
+	"runtime"
+	"time"
+)
+
+// TimeoutReader returns a reader whose Read returns os.ErrDeadlineExceeded if reading from r takes longer than d,
+// for streams like a pipe or a decompressor over a network connection that can't set a read deadline.
+// The reader has a goroutine that calls r.Read on behalf of Read, which exits once the reader is garbage collected.
+// Timing out doesn't cancel the underlying read, so the goroutine stays in r.Read until it returns.
+// Its data isn't lost though, the next Read waits for it instead of reading again.
+// r is read into a buffer that's reused across reads, then copied into p.
+// If d isn't positive, r is returned as is.
+func TimeoutReader(r io.Reader, d time.Duration) io.Reader {
+	if d <= 0 {
+		return r
+	}
+	t := &timeoutReader{}
+	t.init(r, d)
+	runtime.AddCleanup(t, stopTimeoutReader, t.requests)
+	return t
+}
+
+// TimeoutReadSeeker is TimeoutReader for an io.ReadSeeker.
+// Seek waits up to d for a read that timed out to finish, discarding its data, since r's offset isn't known until it does.
+// If the read still hasn't finished, Seek returns os.ErrDeadlineExceeded.
+func TimeoutReadSeeker(r io.ReadSeeker, d time.Duration) io.ReadSeeker {
+	if d <= 0 {
+		return r
+	}
+	t := &timeoutReadSeeker{s: r}
+	t.init(r, d)
+	runtime.AddCleanup(t, stopTimeoutReader, t.requests)
+	return t
+}
+
+type readResult struct {
+	n   int
+	err error
+}
+
+type timeoutReader struct {
+	d time.Duration
+	// timer is reused by every wait.
+	timer *time.Timer
+	// requests sends buf to the goroutine calling r.Read, which sends the result to results.
+	requests chan []byte
+	results  chan readResult
+	// buf is read into by the goroutine calling r.Read. It's only touched by Read while pending is false.
+	buf []byte
+	// pending is true while a read is in progress.
+	pending bool
+	// unread is the part of buf that's been read from r, but not yet copied out by Read.
+	unread []byte
+	// err is the error from r to return once unread is empty.
+	err error
+}
+
+// init starts the goroutine that reads from r. It mustn't reference t, so t can be garbage collected and stop it.
+func (t *timeoutReader) init(r io.Reader, d time.Duration) {
+	t.d = d
+	t.timer = time.NewTimer(d)
+	t.timer.Stop()
+	t.requests = make(chan []byte)
+	t.results = make(chan readResult, 1)
+	go readRequests(r, t.requests, t.results)
+}
+
+func readRequests(r io.Reader, requests <-chan []byte, results chan<- readResult) {
+	for buf := range requests {
+		n, err := r.Read(buf)
+		results <- readResult{n: n, err: err}
+	}
+}
+
+// stopTimeoutReader ends the goroutine of a timeoutReader that's been garbage collected.
+func stopTimeoutReader(requests chan []byte) {
+	close(requests)
+}
+
+func (t *timeoutReader) Read(p []byte) (int, error) {
+	if len(t.unread) == 0 && t.err == nil && len(p) > 0 {
+		if !t.pending {
+			t.start(len(p))
+		}
+		if err := t.wait(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, t.unread)
+	t.unread = t.unread[n:]
+	if len(t.unread) > 0 || t.err == nil {
+		return n, nil
+	}
+	err := t.err
+	t.err = nil
+	return n, err
+}
+
+// start hands a read of up to size bytes from r to the goroutine, which is idle since no read is pending.
+func (t *timeoutReader) start(size int) {
+	if cap(t.buf) < size {
+		t.buf = make([]byte, size)
+	}
+	t.requests <- t.buf[:size]
+	t.pending = true
+}
+
+// wait waits up to d for the read in progress to finish.
+func (t *timeoutReader) wait() error {
+	t.timer.Reset(t.d)
+	defer t.timer.Stop()
+	select {
+	case res := <-t.results:
+		t.pending = false
+		t.unread, t.err = t.buf[:res.n], res.err
+		return nil
+	case <-t.timer.C:
+		return os.ErrDeadlineExceeded
+	}
+}
+
+type timeoutReadSeeker struct {
+	timeoutReader
+	s io.Seeker
+}
+
+func (t *timeoutReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	if t.pending {
+		if err := t.wait(); err != nil {
+			return 0, err
+		}
+	}
+	if whence == io.SeekCurrent {
+		// r's offset is past the data that hasn't been read yet.
+		offset -= int64(len(t.unread))
+	}
+	t.unread, t.err = nil, nil
+	return t.s.Seek(offset, whence)
+}
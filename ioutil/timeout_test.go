@@ -0,0 +1,128 @@
+package ioutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danlock/pkg/test"
+)
+
+// slowReader blocks every Read until a value is sent on release.
+type slowReader struct {
+	io.Reader
+	release chan struct{}
+}
+
+func (r slowReader) Read(p []byte) (int, error) {
+	<-r.release
+	return r.Reader.Read(p)
+}
+
+func TestTimeoutReader(t *testing.T) {
+	fast := TimeoutReader(strings.NewReader("hello world"), time.Second)
+	if b, err := io.ReadAll(fast); err != nil || string(b) != "hello world" {
+		t.Fatalf("unexpected read %q err %v", b, err)
+	}
+
+	slow := slowReader{Reader: strings.NewReader("hello world"), release: make(chan struct{})}
+	r := TimeoutReader(slow, 10*time.Millisecond)
+	p := make([]byte, 5)
+	if n, err := r.Read(p); n != 0 || !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected a timeout, got %d %v", n, err)
+	}
+
+	// The read that timed out still delivers its data to the next Read.
+	close(slow.release)
+	if n, err := r.Read(p); err != nil || string(p[:n]) != "hello" {
+		t.Fatalf("unexpected read %q err %v", p[:n], err)
+	}
+	if b, err := io.ReadAll(r); err != nil || string(b) != " world" {
+		t.Fatalf("unexpected read %q err %v", b, err)
+	}
+
+	if r := strings.NewReader(""); TimeoutReader(r, 0) != r {
+		t.Fatal("expected r to be returned as is")
+	}
+}
+
+func TestTimeoutReadSeeker(t *testing.T) {
+	slow := slowReader{Reader: strings.NewReader("hello world"), release: make(chan struct{})}
+	rs := TimeoutReadSeeker(struct {
+		io.Reader
+		io.Seeker
+	}{slow, slow.Reader.(io.Seeker)}, 10*time.Millisecond)
+
+	p := make([]byte, 20)
+	if _, err := rs.Read(p); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if _, err := rs.Seek(0, io.SeekStart); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected Seek to time out waiting for the read, got %v", err)
+	}
+
+	// Data read beyond p is kept for later reads, and accounted for when seeking relative to the current offset.
+	close(slow.release)
+	if n, err := rs.Read(p[:2]); err != nil || string(p[:n]) != "he" {
+		t.Fatalf("unexpected read %q err %v", p[:n], err)
+	}
+	if pos, err := rs.Seek(-1, io.SeekCurrent); err != nil || pos != 1 {
+		t.Fatalf("unexpected pos %d err %v", pos, err)
+	}
+	if n, err := rs.Read(p[:4]); err != nil || string(p[:n]) != "ello" {
+		t.Fatalf("unexpected read %q err %v", p[:n], err)
+	}
+	if pos, err := rs.Seek(6, io.SeekStart); err != nil || pos != 6 {
+		t.Fatalf("unexpected pos %d err %v", pos, err)
+	}
+	if b, err := io.ReadAll(rs); err != nil || string(b) != "world" {
+		t.Fatalf("unexpected read %q err %v", b, err)
+	}
+}
+
+// zeroReader fills p with zeros forever.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestTimeoutReaderAllocs(t *testing.T) {
+	r := TimeoutReader(zeroReader{}, time.Second)
+	p := make([]byte, 512)
+	if allocs := testing.AllocsPerRun(100, func() { r.Read(p) }); allocs != 0 {
+		t.Fatalf("expected Read to reuse the goroutine, buffer and timer, got %v allocs", allocs)
+	}
+}
+
+func TestTimeoutReaderGoroutineExits(t *testing.T) {
+	before := runtime.NumGoroutine()
+	r := TimeoutReader(zeroReader{}, time.Second)
+	if _, err := r.Read(make([]byte, 8)); err != nil {
+		t.Fatal(err)
+	}
+	r = nil
+	test.AssertEventuallyFunc(t, func() (bool, string) {
+		runtime.GC()
+		n := runtime.NumGoroutine()
+		return n <= before, fmt.Sprintf("%d goroutines, %d before", n, before)
+	}, 5*time.Second, time.Millisecond, "expected the goroutine to exit once the reader was collected")
+}
+
+func BenchmarkTimeoutReader(b *testing.B) {
+	r := TimeoutReader(zeroReader{}, time.Second)
+	p := make([]byte, 32*1024)
+	b.SetBytes(int64(len(p)))
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := r.Read(p); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package ioutil
+
+import (
+	"io"
+
+	"github.com/danlock/pkg/errors"
+)
+
+// WriteAtSeeker adapts w to an io.WriteSeeker by tracking the position of the next Write, starting at 0.
+// size is the current size of w, used to Seek relative to io.SeekEnd. Writes past the end grow the size.
+// The returned io.WriteSeeker isn't safe for concurrent use.
+func WriteAtSeeker(w io.WriterAt, size int64) io.WriteSeeker {
+	return &writeAtSeeker{w: w, size: size}
+}
+
+type writeAtSeeker struct {
+	w    io.WriterAt
+	pos  int64
+	size int64
+}
+
+func (ws *writeAtSeeker) Write(p []byte) (int, error) {
+	n, err := ws.w.WriteAt(p, ws.pos)
+	ws.pos += int64(n)
+	ws.size = max(ws.size, ws.pos)
+	return n, err
+}
+
+func (ws *writeAtSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += ws.pos
+	case io.SeekEnd:
+		offset += ws.size
+	default:
+		return 0, errors.Errorf("invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, errors.Errorf("negative position %d", offset)
+	}
+	ws.pos = offset
+	return ws.pos, nil
+}
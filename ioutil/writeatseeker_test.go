@@ -0,0 +1,52 @@
+package ioutil
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteAtSeeker(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString("hello world"); err != nil {
+		t.Fatal(err)
+	}
+
+	ws := WriteAtSeeker(f, 11)
+	seekAndWrite := func(offset int64, whence int, wantPos int64, s string) {
+		t.Helper()
+		pos, err := ws.Seek(offset, whence)
+		if err != nil || pos != wantPos {
+			t.Fatalf("Seek(%d, %d) == %d, %v", offset, whence, pos, err)
+		}
+		if _, err := io.WriteString(ws, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seekAndWrite(0, io.SeekStart, 0, "H")
+	seekAndWrite(5, io.SeekCurrent, 6, "W")
+	seekAndWrite(0, io.SeekEnd, 11, "!")
+	// The size grows with writes past the end.
+	seekAndWrite(-1, io.SeekEnd, 11, "?")
+
+	got, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "Hello World?" {
+		t.Fatalf("unexpected contents %q", got)
+	}
+
+	if _, err := ws.Seek(-13, io.SeekEnd); err == nil {
+		t.Fatal("expected an error seeking to a negative position")
+	}
+	if _, err := ws.Seek(0, 42); err == nil {
+		t.Fatal("expected an error for an invalid whence")
+	}
+}
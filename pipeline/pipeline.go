@@ -0,0 +1,122 @@
+// Package pipeline connects concurrent stages of stream processing with iterators, with backpressure between stages.
+package pipeline
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// Stage processes the values of in concurrently, returning an iterator of the results and an iterator of the errors.
+// Calling a Stage starts its workers, so the values must be ranged over until they run out or the loop breaks, which stops the workers.
+// Workers block until their results are taken, so the errors should be ranged over after the values, since they're only yielded once the Stage has finished.
+// If ctx finishes before in runs out, the workers stop and ctx.Err() is the last error.
+type Stage[A, B any] func(ctx context.Context, in iter.Seq[A]) (iter.Seq[B], iter.Seq[error])
+
+// NewStage returns a Stage that calls fn on every value with workers goroutines, at least 1.
+// Values are yielded in the order fn finishes with them. Errors from fn are yielded by the error iterator, and the value returned alongside them is dropped.
+func NewStage[A, B any](workers int, fn func(context.Context, A) (B, error)) Stage[A, B] {
+	workers = max(workers, 1)
+	return func(parent context.Context, in iter.Seq[A]) (iter.Seq[B], iter.Seq[error]) {
+		ctx, cancel := context.WithCancel(parent)
+		jobs := make(chan A)
+		out := make(chan B)
+		done := make(chan struct{})
+		var mu sync.Mutex
+		var errs []error
+
+		go func() {
+			defer close(jobs)
+			for a := range in {
+				select {
+				case jobs <- a:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for range workers {
+			go func() {
+				defer wg.Done()
+				for {
+					var a A
+					select {
+					case job, ok := <-jobs:
+						if !ok {
+							return
+						}
+						a = job
+					case <-ctx.Done():
+						// in may be blocked, so don't wait for jobs to be closed.
+						return
+					}
+					b, err := fn(ctx, a)
+					if err != nil {
+						mu.Lock()
+						errs = append(errs, err)
+						mu.Unlock()
+						continue
+					}
+					select {
+					case out <- b:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+		}
+
+		go func() {
+			wg.Wait()
+			if err := parent.Err(); err != nil {
+				errs = append(errs, err)
+			}
+			cancel()
+			close(out)
+			close(done)
+		}()
+
+		values := func(yield func(B) bool) {
+			for b := range out {
+				if !yield(b) {
+					cancel()
+					return
+				}
+			}
+		}
+		errors := func(yield func(error) bool) {
+			<-done
+			for _, err := range errs {
+				if !yield(err) {
+					return
+				}
+			}
+		}
+		return values, errors
+	}
+}
+
+// Chain returns a Stage that feeds the results of s1 into s2.
+// Its errors are those of s1 followed by those of s2.
+func Chain[A, B, C any](s1 Stage[A, B], s2 Stage[B, C]) Stage[A, C] {
+	return func(ctx context.Context, in iter.Seq[A]) (iter.Seq[C], iter.Seq[error]) {
+		mid, errs1 := s1(ctx, in)
+		out, errs2 := s2(ctx, mid)
+		errors := func(yield func(error) bool) {
+			for err := range errs1 {
+				if !yield(err) {
+					return
+				}
+			}
+			for err := range errs2 {
+				if !yield(err) {
+					return
+				}
+			}
+		}
+		return out, errors
+	}
+}
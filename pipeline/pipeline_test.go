@@ -0,0 +1,138 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStage(t *testing.T) {
+	errOdd := errors.New("odd")
+	square := NewStage(4, func(_ context.Context, i int) (int, error) {
+		if i%2 == 1 {
+			return 0, fmt.Errorf("%d: %w", i, errOdd)
+		}
+		return i * i, nil
+	})
+
+	values, errs := square(context.Background(), slices.Values([]int{1, 2, 3, 4, 5, 6}))
+	if got := slices.Sorted(values); !slices.Equal(got, []int{4, 16, 36}) {
+		t.Fatalf("unexpected values %v", got)
+	}
+	got := slices.Collect(errs)
+	if len(got) != 3 {
+		t.Fatalf("expected an error for every odd value, got %v", got)
+	}
+	for _, err := range got {
+		if !errors.Is(err, errOdd) {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+}
+
+func TestStageBreak(t *testing.T) {
+	var calls atomic.Int32
+	stage := NewStage(2, func(_ context.Context, i int) (int, error) {
+		calls.Add(1)
+		return i, nil
+	})
+	endless := func(yield func(int) bool) {
+		for i := 0; yield(i); i++ {
+		}
+	}
+
+	values, errs := stage(context.Background(), endless)
+	for range values {
+		break
+	}
+	if got := slices.Collect(errs); len(got) != 0 {
+		t.Fatalf("unexpected errors %v", got)
+	}
+	stopped := calls.Load()
+	time.Sleep(10 * time.Millisecond)
+	if calls.Load() != stopped {
+		t.Fatalf("workers kept running after the loop broke")
+	}
+}
+
+func TestStageCtx(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stage := NewStage(2, func(_ context.Context, i int) (int, error) {
+		if i == 3 {
+			cancel()
+		}
+		return i, nil
+	})
+	endless := func(yield func(int) bool) {
+		for i := 0; yield(i); i++ {
+		}
+	}
+
+	values, errs := stage(ctx, endless)
+	for range values {
+	}
+	got := slices.Collect(errs)
+	if len(got) == 0 || !errors.Is(got[len(got)-1], context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", got)
+	}
+}
+
+func TestStageCtxBlockedSource(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	unblock := make(chan struct{})
+	defer close(unblock)
+	blocked := func(yield func(int) bool) {
+		if !yield(1) {
+			return
+		}
+		<-unblock
+	}
+	stage := NewStage(2, func(_ context.Context, i int) (int, error) { return i, nil })
+
+	values, errs := stage(ctx, blocked)
+	done := make(chan []int)
+	go func() { done <- slices.Collect(values) }()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case got := <-done:
+		if !slices.Equal(got, []int{1}) {
+			t.Fatalf("unexpected values %v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stage didn't stop after ctx was cancelled while its source was blocked")
+	}
+	if got := slices.Collect(errs); len(got) != 1 || !errors.Is(got[0], context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", got)
+	}
+}
+
+func TestChain(t *testing.T) {
+	double := NewStage(3, func(_ context.Context, i int) (int, error) {
+		if i < 0 {
+			return 0, errors.New("negative")
+		}
+		return i * 2, nil
+	})
+	format := NewStage(2, func(_ context.Context, i int) (string, error) {
+		if i == 4 {
+			return "", errors.New("four")
+		}
+		return strconv.Itoa(i), nil
+	})
+
+	values, errs := Chain(double, format)(context.Background(), slices.Values([]int{-1, 1, 2, 3}))
+	if got := slices.Sorted(values); !slices.Equal(got, []string{"2", "6"}) {
+		t.Fatalf("unexpected values %v", got)
+	}
+	got := slices.Collect(errs)
+	if len(got) != 2 || got[0].Error() != "negative" || got[1].Error() != "four" {
+		t.Fatalf("unexpected errors %v", got)
+	}
+}
@@ -0,0 +1,34 @@
+package ptr
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/danlock/pkg/errors"
+)
+
+// DeepCopy copies v by round tripping it through encoding/gob, so the copy shares no memory with v.
+// It returns an error if v can't be encoded by gob, such as when it contains a func or a chan.
+// Like gob, unexported fields aren't copied and are left as the zero value in the copy,
+// and gob's other quirks apply, such as empty slices and maps becoming nil.
+// encoding/json is an alternative with similar rules if T has json tags to respect.
+func DeepCopy[T any](v T) (T, error) {
+	var buf bytes.Buffer
+	var copied T
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return copied, errors.Wrap(err)
+	}
+	if err := gob.NewDecoder(&buf).Decode(&copied); err != nil {
+		return copied, errors.Wrap(err)
+	}
+	return copied, nil
+}
+
+// MustDeepCopy is DeepCopy, but panics on error. Useful for snapshotting values in tests.
+func MustDeepCopy[T any](v T) T {
+	copied, err := DeepCopy(v)
+	if err != nil {
+		panic(err)
+	}
+	return copied
+}
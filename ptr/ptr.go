@@ -5,6 +5,17 @@ func To[T any](s T) *T {
 	return &s
 }
 
+// Of returns a pointer to v if ok, or nil otherwise, for turning the comma-ok result of a map lookup or type assertion into an optional value.
+//
+//	v, ok := m[key]
+//	return ptr.Of(v, ok)
+func Of[T any](v T, ok bool) *T {
+	if !ok {
+		return nil
+	}
+	return &v
+}
+
 // From dereferences a pointer by returning the zero value if null
 func From[T any](p *T) (zero T) {
 	if p == nil {
@@ -13,3 +24,73 @@ func From[T any](p *T) (zero T) {
 		return *p
 	}
 }
+
+// IfNil dereferences a pointer by returning fallback if null, unlike From which can't tell nil from a pointer to the zero value.
+func IfNil[T any](p *T, fallback T) T {
+	if p == nil {
+		return fallback
+	}
+	return *p
+}
+
+// IfNilFunc is IfNil, but only calls fallback if p is nil, for defaults that are expensive to create.
+func IfNilFunc[T any](p *T, fallback func() T) T {
+	if p == nil {
+		return fallback()
+	}
+	return *p
+}
+
+// Swap sets the value p points to and returns the previous value.
+// If p is nil, nothing is set and the zero value is returned, matching From.
+func Swap[T any](p *T, new T) (old T) {
+	if p == nil {
+		return old
+	}
+	old, *p = *p, new
+	return old
+}
+
+// SetIf sets the value p points to if cond is true, for terse conditional updates when applying options.
+// Like Swap, it's a no-op if p is nil.
+func SetIf[T any](p *T, cond bool, v T) {
+	if cond && p != nil {
+		*p = v
+	}
+}
+
+// All returns true if every one of ptrs is non-nil.
+func All[T any](ptrs ...*T) bool {
+	for _, p := range ptrs {
+		if p == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Any returns true if at least one of ptrs is non-nil.
+func Any[T any](ptrs ...*T) bool {
+	for _, p := range ptrs {
+		if p != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// None returns true if every one of ptrs is nil.
+func None[T any](ptrs ...*T) bool {
+	return !Any(ptrs...)
+}
+
+// Filter returns a new slice of the non-nil pointers of ptrs.
+func Filter[T any](ptrs []*T) []*T {
+	filtered := make([]*T, 0, len(ptrs))
+	for _, p := range ptrs {
+		if p != nil {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,149 @@
+package ptr
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSwap(t *testing.T) {
+	v := 1
+	if old := Swap(&v, 2); old != 1 || v != 2 {
+		t.Fatalf("unexpected old %d new %d", old, v)
+	}
+	if old := Swap(&v, 3); old != 2 || v != 3 {
+		t.Fatalf("unexpected old %d new %d", old, v)
+	}
+
+	type opts struct{ Name *string }
+	o := opts{Name: To("a")}
+	if old := Swap(&o.Name, To("b")); From(old) != "a" || From(o.Name) != "b" {
+		t.Fatalf("unexpected old %v new %v", old, o.Name)
+	}
+
+	if old := Swap[int](nil, 5); old != 0 {
+		t.Fatalf("expected the zero value for a nil pointer, got %d", old)
+	}
+}
+
+func TestSetIf(t *testing.T) {
+	v := 1
+	SetIf(&v, false, 2)
+	if v != 1 {
+		t.Fatalf("expected no change when cond is false, got %d", v)
+	}
+	SetIf(&v, true, 3)
+	if v != 3 {
+		t.Fatalf("expected 3, got %d", v)
+	}
+	SetIf[int](nil, true, 4)
+}
+
+func TestDeepCopy(t *testing.T) {
+	type inner struct{ Tags []string }
+	type outer struct {
+		Name     string
+		Inner    *inner
+		Counts   map[string]int
+		internal int
+	}
+	orig := outer{Name: "a", Inner: &inner{Tags: []string{"x", "y"}}, Counts: map[string]int{"a": 1}, internal: 5}
+
+	copied, err := DeepCopy(orig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copied.Name != "a" || copied.Inner == orig.Inner || !slices.Equal(copied.Inner.Tags, orig.Inner.Tags) || copied.Counts["a"] != 1 {
+		t.Fatalf("unexpected copy %+v", copied)
+	}
+	if copied.internal != 0 {
+		t.Fatal("expected unexported fields to be left out")
+	}
+
+	copied.Inner.Tags[0] = "changed"
+	copied.Counts["a"] = 2
+	if orig.Inner.Tags[0] != "x" || orig.Counts["a"] != 1 {
+		t.Fatalf("the copy shares memory with the original %+v", orig)
+	}
+
+	if _, err := DeepCopy(struct{ Fn func() }{Fn: func() {}}); err == nil {
+		t.Fatal("expected an error copying a func")
+	}
+
+	if got := MustDeepCopy([]int{1, 2}); !slices.Equal(got, []int{1, 2}) {
+		t.Fatalf("unexpected copy %v", got)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected MustDeepCopy to panic")
+		}
+	}()
+	MustDeepCopy(make(chan int))
+}
+
+func TestIfNil(t *testing.T) {
+	if got := IfNil(nil, 5); got != 5 {
+		t.Fatalf("expected the fallback, got %d", got)
+	}
+	if got := IfNil(To(0), 5); got != 0 {
+		t.Fatalf("expected the pointed to zero value, got %d", got)
+	}
+
+	calls := 0
+	fallback := func() string {
+		calls++
+		return "default"
+	}
+	if got := IfNilFunc(To(""), fallback); got != "" || calls != 0 {
+		t.Fatalf("unexpected %q with %d fallback calls", got, calls)
+	}
+	if got := IfNilFunc(nil, fallback); got != "default" || calls != 1 {
+		t.Fatalf("unexpected %q with %d fallback calls", got, calls)
+	}
+}
+
+func TestOf(t *testing.T) {
+	m := map[string]int{"zero": 0}
+	v, ok := m["zero"]
+	if p := Of(v, ok); p == nil || *p != 0 {
+		t.Fatalf("expected a pointer to 0, got %v", p)
+	}
+	v, ok = m["missing"]
+	if p := Of(v, ok); p != nil {
+		t.Fatalf("expected nil, got %v", *p)
+	}
+
+	var a any = "str"
+	str, ok := a.(string)
+	if p := Of(str, ok); p == nil || *p != "str" {
+		t.Fatalf("unexpected %v", p)
+	}
+	i, ok := a.(int)
+	if p := Of(i, ok); p != nil {
+		t.Fatalf("expected nil, got %v", *p)
+	}
+}
+
+func TestAllAnyNone(t *testing.T) {
+	a, b := To(1), To(2)
+	tests := []struct {
+		ptrs           []*int
+		all, any, none bool
+	}{
+		{[]*int{a, b}, true, true, false},
+		{[]*int{a, nil}, false, true, false},
+		{[]*int{nil, nil}, false, false, true},
+		{nil, true, false, true},
+	}
+	for i, tt := range tests {
+		if All(tt.ptrs...) != tt.all || Any(tt.ptrs...) != tt.any || None(tt.ptrs...) != tt.none {
+			t.Fatalf("%d: unexpected All %t Any %t None %t", i, All(tt.ptrs...), Any(tt.ptrs...), None(tt.ptrs...))
+		}
+	}
+
+	if got := Filter([]*int{nil, a, nil, b}); !slices.Equal(got, []*int{a, b}) {
+		t.Fatalf("unexpected Filter %v", got)
+	}
+	if got := Filter[int](nil); got == nil || len(got) != 0 {
+		t.Fatalf("unexpected Filter %v", got)
+	}
+}
@@ -0,0 +1,83 @@
+package retry
+
+import "time"
+
+// AdaptiveDelay is a delay that follows the failure rate of recent attempts instead of a fixed schedule.
+// While at most half of the recent attempts failed the delay stays at Min, then it grows linearly to Max as the failure rate reaches 100%.
+// Report the outcome of every attempt with Observe and use Delay as the delay function.
+// AdaptiveDelay isn't safe for concurrent use, it's meant for a single retry loop.
+// NewAdaptiveDelay sets the size of the window. A literal or zero AdaptiveDelay is usable too, but only considers the last outcome.
+type AdaptiveDelay struct {
+	// Min is the delay while the dependency is healthy.
+	Min time.Duration
+	// Max is the delay once every recent attempt failed.
+	Max time.Duration
+	// MaxAge ignores outcomes older than it when greater than 0, so a quiet period forgets old failures.
+	MaxAge time.Duration
+
+	outcomes []outcome
+	next     int
+	now      func() time.Time
+}
+
+type outcome struct {
+	at     time.Time
+	failed bool
+}
+
+// NewAdaptiveDelay creates an AdaptiveDelay considering the last window outcomes.
+func NewAdaptiveDelay(window int, minDelay, maxDelay time.Duration) *AdaptiveDelay {
+	return &AdaptiveDelay{Min: minDelay, Max: maxDelay, outcomes: make([]outcome, 0, max(window, 1)), now: time.Now}
+}
+
+// Observe records the outcome of an attempt, replacing the oldest outcome once the window is full.
+func (a *AdaptiveDelay) Observe(failed bool) {
+	if cap(a.outcomes) == 0 {
+		a.outcomes = make([]outcome, 0, 1)
+	}
+	o := outcome{at: a.clock(), failed: failed}
+	if len(a.outcomes) < cap(a.outcomes) {
+		a.outcomes = append(a.outcomes, o)
+	} else {
+		a.outcomes[a.next] = o
+	}
+	a.next = (a.next + 1) % cap(a.outcomes)
+}
+
+// clock returns the current time from now, which tests replace, or time.Now for a literal AdaptiveDelay.
+func (a *AdaptiveDelay) clock() time.Time {
+	if a.now == nil {
+		return time.Now()
+	}
+	return a.now()
+}
+
+// FailureRate returns the fraction of failed outcomes within the window, or 0 without any outcomes.
+func (a *AdaptiveDelay) FailureRate() float64 {
+	var total, failed int
+	for _, o := range a.outcomes {
+		if a.MaxAge > 0 && a.clock().Sub(o.at) > a.MaxAge {
+			continue
+		}
+		total++
+		if o.failed {
+			failed++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(failed) / float64(total)
+}
+
+// Delay returns the delay for the current failure rate. Like FibonacciDelay, attempt 0 is 0.
+func (a *AdaptiveDelay) Delay(attempt uint) time.Duration {
+	if attempt == 0 {
+		return 0
+	}
+	rate := a.FailureRate()
+	if rate <= 0.5 {
+		return a.Min
+	}
+	return a.Min + time.Duration(float64(a.Max-a.Min)*(rate-0.5)*2)
+}
@@ -0,0 +1,73 @@
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveDelay(t *testing.T) {
+	now := time.Now()
+	a := NewAdaptiveDelay(4, time.Second, 9*time.Second)
+	a.now = func() time.Time { return now }
+
+	if d := a.Delay(1); d != time.Second {
+		t.Fatalf("expected Min without outcomes, got %v", d)
+	}
+
+	// Each step observes an outcome and checks the delay afterwards.
+	steps := []struct {
+		failed bool
+		want   time.Duration
+	}{
+		{false, time.Second},
+		{true, time.Second},
+		{true, time.Second + 8*time.Second/3}, // 2/3 failed
+		{true, 5 * time.Second},               // 3/4 failed
+		{true, 9 * time.Second},               // the window is full of failures
+		{true, 9 * time.Second},
+		{false, 5 * time.Second}, // 3/4 failed again as the oldest failures leave the window
+		{false, time.Second},
+		{false, time.Second},
+		{false, time.Second},
+	}
+	for i, s := range steps {
+		a.Observe(s.failed)
+		if got := a.Delay(1); got != s.want {
+			t.Fatalf("step %d: delay %v at failure rate %v, expected %v", i, got, a.FailureRate(), s.want)
+		}
+	}
+	if d := a.Delay(0); d != 0 {
+		t.Fatalf("expected attempt 0 to be 0, got %v", d)
+	}
+
+	// Old outcomes are forgotten after MaxAge.
+	a.MaxAge = time.Minute
+	for range 4 {
+		a.Observe(true)
+	}
+	if d := a.Delay(1); d != 9*time.Second {
+		t.Fatalf("unexpected delay %v", d)
+	}
+	now = now.Add(2 * time.Minute)
+	if d := a.Delay(1); d != time.Second {
+		t.Fatalf("expected old failures to be forgotten, got %v", d)
+	}
+}
+
+func TestAdaptiveDelayLiteral(t *testing.T) {
+	var zero AdaptiveDelay
+	zero.Observe(true)
+	if rate := zero.FailureRate(); rate != 1 {
+		t.Fatalf("expected the zero value to track the last outcome, got %v", rate)
+	}
+
+	a := &AdaptiveDelay{Min: time.Second, Max: 3 * time.Second, MaxAge: time.Minute}
+	a.Observe(true)
+	if d := a.Delay(1); d != 3*time.Second {
+		t.Fatalf("expected Max after a failure, got %v", d)
+	}
+	a.Observe(false)
+	if d := a.Delay(1); d != time.Second {
+		t.Fatalf("expected only the last outcome to count, got %v", d)
+	}
+}
@@ -0,0 +1,216 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/danlock/pkg/errors"
+)
+
+// Options configures how Do retries.
+type Options struct {
+	// MaxAttempts is the number of calls to fn before Do gives up. Do retries until the context finishes when it's 0.
+	MaxAttempts uint
+	// Delay returns the backoff after a failed attempt. FibonacciDelay is used when nil.
+	Delay func(attempt uint) time.Duration
+	// InitialDelay is the least Do waits before the first retry, for dependencies known to need time to recover.
+	// The larger of InitialDelay and Delay(1) is used.
+	InitialDelay time.Duration
+	// DelayBeforeFirstAttempt makes Do wait InitialDelay before the first attempt as well,
+	// such as when triggered by something known to race replication.
+	DelayBeforeFirstAttempt bool
+	// AttemptTimeout limits each call to fn if set. DoCtx's fn is given a ctx derived with the timeout,
+	// which is cancelled once fn returns.
+	AttemptTimeout time.Duration
+	// OnRetry is called with the error of every failed attempt that is about to be retried.
+	OnRetry func(attempt uint, err error)
+	// CollectAttemptErrors joins the errors of the failed attempts into the error returned by Do, as long as OnRetry is nil.
+	CollectAttemptErrors bool
+	// MaxCollectedErrors is the number of the most recent errors CollectAttemptErrors keeps. 10 are kept when it's 0.
+	MaxCollectedErrors int
+	// Precondition is checked before every attempt if set, such as to skip calling a dependency known to be down.
+	// When it returns an error, fn isn't called and the attempt fails with that error, backing off as usual.
+	Precondition func(ctx context.Context) error
+	// PreconditionFailFast makes Do return the error from Precondition immediately instead of backing off, wrapped with ErrBreakerOpen.
+	PreconditionFailFast bool
+	// AllowAttempt is consulted before every attempt if set, such as to avoid a maintenance window.
+	// When it returns false, Do waits until resumeAt and asks again instead of calling fn, without counting an attempt.
+	// Do waits at least 10ms between checks, so a resumeAt that's zero or already past doesn't spin.
+	AllowAttempt func(now time.Time) (ok bool, resumeAt time.Time)
+}
+
+const defaultMaxCollectedErrors = 10
+
+// minAllowAttemptWait is the least Do waits after AllowAttempt rejects an attempt.
+const minAllowAttemptWait = 10 * time.Millisecond
+
+// Do calls fn until it returns nil, backing off between failed attempts according to opts.
+// A backoff that outlasts the context's deadline isn't shortened, Do gives up once the context finishes.
+// When Do gives up because the context finished or MaxAttempts was reached, it returns the last error from fn,
+// or ctx.Err() if fn was never called. Running out of attempts also wraps ErrMaxAttemptsReached, and running past the context's deadline wraps ErrBudgetExhausted.
+// With PreconditionFailFast, a failed Precondition is returned wrapping ErrBreakerOpen. The error is wrapped with the number of attempts and the time spent retrying as attrs,
+// so logging it with log/slog shows how the retries went.
+func Do(ctx context.Context, opts Options, fn func() error) error {
+	rec, err := run(ctx, opts, func(context.Context, uint) error { return fn() })
+	return errors.WrapAttr(err, rec.attrs()...)
+}
+
+// DoCtx is Do, but fn is also given ctx and the current attempt.
+// attempt is the number of failed calls before this one, so it starts at 0.
+// It's the same value opts.Delay was given for the backoff before this call.
+func DoCtx(ctx context.Context, opts Options, fn func(ctx context.Context, attempt uint) error) error {
+	rec, err := run(ctx, opts, fn)
+	return errors.WrapAttr(err, rec.attrs()...)
+}
+
+// DoValue is Do for a fn that also returns a value, returning the value from the successful call.
+func DoValue[T any](ctx context.Context, opts Options, fn func() (T, error)) (T, error) {
+	val, rec, err := runValue(ctx, opts, fn)
+	return val, errors.WrapAttr(err, rec.attrs()...)
+}
+
+// DoValueOr is DoValue, but calls fallback with the error once it gives up, such as to serve a stale value.
+// fallback can return a replacement value with a nil error, or an error that's joined with the retry error
+// so neither is lost. fallback can also return the error it was given.
+func DoValueOr[T any](ctx context.Context, opts Options, fn func() (T, error), fallback func(lastErr error) (T, error)) (T, error) {
+	val, rec, err := runValue(ctx, opts, fn)
+	if err == nil {
+		return val, nil
+	}
+
+	err = errors.WrapAttr(err, rec.attrs()...)
+	val, fallbackErr := fallback(err)
+	if fallbackErr != nil && !errors.Is(fallbackErr, err) {
+		fallbackErr = errors.Join(err, fallbackErr)
+	}
+	return val, fallbackErr
+}
+
+func runValue[T any](ctx context.Context, opts Options, fn func() (T, error)) (val T, rec Record, err error) {
+	rec, err = run(ctx, opts, func(context.Context, uint) error {
+		val, err = fn()
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, rec, err
+	}
+	return val, rec, nil
+}
+
+// Record describes a finished retry loop.
+type Record struct {
+	// Attempts is the number of attempts, including those where fn wasn't called because Precondition failed.
+	Attempts uint
+	// Elapsed is the time spent from the first call until the loop finished.
+	Elapsed time.Duration
+	// Succeeded is true if fn returned nil before the context finished.
+	Succeeded bool
+}
+
+// RunRecord is Do, but also returns a Record of the loop for analytics.
+func RunRecord(ctx context.Context, opts Options, fn func() error) (Record, error) {
+	rec, err := run(ctx, opts, func(context.Context, uint) error { return fn() })
+	return rec, errors.WrapAttr(err, rec.attrs()...)
+}
+
+func (r Record) attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.Uint64("retry_attempts", uint64(r.Attempts)),
+		slog.Duration("retry_elapsed", r.Elapsed),
+	}
+}
+
+// run is the retry loop of Do. Its error isn't wrapped.
+func run(ctx context.Context, opts Options, fn func(ctx context.Context, attempt uint) error) (rec Record, err error) {
+	delay := opts.Delay
+	if delay == nil {
+		delay = FibonacciDelay
+	}
+	maxCollected := opts.MaxCollectedErrors
+	if maxCollected <= 0 {
+		maxCollected = defaultMaxCollectedErrors
+	}
+	collect := opts.CollectAttemptErrors && opts.OnRetry == nil
+
+	start := time.Now()
+	defer func() { rec.Elapsed = time.Since(start) }()
+	var collected []error
+	if opts.DelayBeforeFirstAttempt {
+		sleep(ctx, opts.InitialDelay)
+	}
+	for ctx.Err() == nil {
+		if opts.AllowAttempt != nil {
+			if ok, resumeAt := opts.AllowAttempt(time.Now()); !ok {
+				if !sleep(ctx, max(time.Until(resumeAt), minAllowAttemptWait)) {
+					break
+				}
+				continue
+			}
+		}
+
+		err = nil
+		if opts.Precondition != nil {
+			if err = opts.Precondition(ctx); err != nil && opts.PreconditionFailFast {
+				return rec, fmt.Errorf("%w: %w", ErrBreakerOpen, err)
+			}
+		}
+		if err == nil {
+			err = callAttempt(ctx, opts.AttemptTimeout, rec.Attempts, fn)
+		}
+		rec.Attempts++
+		if err == nil {
+			rec.Succeeded = true
+			return rec, nil
+		}
+		if collect {
+			if len(collected) == maxCollected {
+				collected = append(collected[:0], collected[1:]...)
+			}
+			collected = append(collected, err)
+		}
+		if opts.MaxAttempts > 0 && rec.Attempts >= opts.MaxAttempts {
+			return rec, fmt.Errorf("%w: %w", ErrMaxAttemptsReached, attemptErr(err, collected))
+		}
+		if opts.OnRetry != nil {
+			opts.OnRetry(rec.Attempts, err)
+		}
+		d := delay(rec.Attempts)
+		if rec.Attempts == 1 {
+			d = max(d, opts.InitialDelay)
+		}
+		if !sleep(ctx, d) {
+			break
+		}
+	}
+
+	if err == nil {
+		err = ctx.Err()
+	} else {
+		err = attemptErr(err, collected)
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%w: %w", ErrBudgetExhausted, err)
+	}
+	return rec, err
+}
+
+// callAttempt calls fn with a ctx limited to timeout, unless timeout is 0.
+func callAttempt(ctx context.Context, timeout time.Duration, attempt uint, fn func(ctx context.Context, attempt uint) error) error {
+	if timeout <= 0 {
+		return fn(ctx, attempt)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return fn(ctx, attempt)
+}
+
+// attemptErr returns the errors collected from every attempt, or just the last error.
+func attemptErr(last error, collected []error) error {
+	if len(collected) > 1 {
+		return errors.Join(collected...)
+	}
+	return last
+}
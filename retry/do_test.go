@@ -0,0 +1,450 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/danlock/pkg/errors"
+)
+
+func noDelay(uint) time.Duration { return 0 }
+
+func TestDo(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	calls := 0
+	err := Do(ctx, Options{Delay: noDelay}, func() error {
+		if calls++; calls < 3 {
+			return fmt.Errorf("attempt %d", calls)
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("unexpected err %v calls %d", err, calls)
+	}
+
+	errFail := errors.New("fail")
+	calls = 0
+	err = Do(ctx, Options{MaxAttempts: 3, Delay: noDelay}, func() error {
+		calls++
+		return errFail
+	})
+	if !errors.Is(err, errFail) || calls != 3 {
+		t.Fatalf("unexpected err %v calls %d", err, calls)
+	}
+	meta := errors.UnwrapAttr(err)
+	if meta["retry_attempts"].Uint64() != 3 {
+		t.Fatalf("unexpected attrs %v", meta)
+	}
+	if _, ok := meta["retry_elapsed"]; !ok {
+		t.Fatalf("missing retry_elapsed in %v", meta)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := Do(cancelled, Options{}, func() error { return errFail }); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestDoCollectAttemptErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	attemptErrs := []error{errors.New("one"), errors.New("two"), errors.New("three"), errors.New("four")}
+	calls := 0
+	fn := func() error {
+		calls++
+		return attemptErrs[calls-1]
+	}
+
+	err := Do(ctx, Options{MaxAttempts: 4, Delay: noDelay, CollectAttemptErrors: true, MaxCollectedErrors: 3}, fn)
+	if errors.Is(err, attemptErrs[0]) {
+		t.Fatalf("expected the oldest error to be dropped, got %v", err)
+	}
+	for _, attemptErr := range attemptErrs[1:] {
+		if !errors.Is(err, attemptErr) {
+			t.Fatalf("expected %v to contain %v", err, attemptErr)
+		}
+	}
+
+	// OnRetry takes over reporting the intermediate errors.
+	calls = 0
+	var retried []uint
+	err = Do(ctx, Options{
+		MaxAttempts:          4,
+		Delay:                noDelay,
+		CollectAttemptErrors: true,
+		OnRetry:              func(attempt uint, err error) { retried = append(retried, attempt) },
+	}, fn)
+	if !errors.Is(err, attemptErrs[3]) || errors.Is(err, attemptErrs[2]) {
+		t.Fatalf("expected only the last error, got %v", err)
+	}
+	if len(retried) != 3 || retried[2] != 3 {
+		t.Fatalf("unexpected OnRetry calls %v", retried)
+	}
+}
+
+func TestRunRecord(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	calls := 0
+	rec, err := RunRecord(ctx, Options{Delay: func(uint) time.Duration { return time.Millisecond }}, func() error {
+		if calls++; calls < 3 {
+			return errors.New("fail")
+		}
+		return nil
+	})
+	if err != nil || rec.Attempts != 3 || !rec.Succeeded || rec.Elapsed < 2*time.Millisecond {
+		t.Fatalf("unexpected record %+v err %v", rec, err)
+	}
+
+	rec, err = RunRecord(ctx, Options{MaxAttempts: 2, Delay: noDelay}, func() error { return errors.New("fail") })
+	if err == nil || rec.Attempts != 2 || rec.Succeeded {
+		t.Fatalf("unexpected record %+v err %v", rec, err)
+	}
+
+	// The loop stops once the context finishes.
+	cancelled, cancel := context.WithCancel(ctx)
+	rec, err = RunRecord(cancelled, Options{Delay: noDelay}, func() error {
+		cancel()
+		return errors.New("fail")
+	})
+	if err == nil || rec.Succeeded || rec.Attempts != 1 {
+		t.Fatalf("unexpected record %+v err %v", rec, err)
+	}
+}
+
+func TestDoCtx(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var fnAttempts, delayAttempts []uint
+	err := DoCtx(ctx, Options{Delay: func(attempt uint) time.Duration {
+		delayAttempts = append(delayAttempts, attempt)
+		return 0
+	}}, func(fnCtx context.Context, attempt uint) error {
+		if fnCtx != ctx {
+			t.Fatal("expected fn to be given ctx")
+		}
+		if fnAttempts = append(fnAttempts, attempt); attempt < 2 {
+			return errors.New("fail")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(fnAttempts, []uint{0, 1, 2}) || !slices.Equal(delayAttempts, []uint{1, 2}) {
+		t.Fatalf("unexpected fn attempts %v delay attempts %v", fnAttempts, delayAttempts)
+	}
+}
+
+func TestDoValueOr(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	calls := 0
+	val, err := DoValue(ctx, Options{Delay: noDelay}, func() (int, error) {
+		if calls++; calls < 3 {
+			return -1, errors.New("fail")
+		}
+		return calls, nil
+	})
+	if err != nil || val != 3 {
+		t.Fatalf("unexpected val %d err %v", val, err)
+	}
+
+	errFail := errors.New("fail")
+	opts := Options{MaxAttempts: 2, Delay: noDelay}
+	failing := func() (string, error) { return "partial", errFail }
+	if val, err := DoValue(ctx, opts, failing); val != "" || !errors.Is(err, errFail) {
+		t.Fatalf("unexpected val %q err %v", val, err)
+	}
+
+	fallbackCalls := 0
+	val2, err := DoValueOr(ctx, opts, func() (string, error) { return "fresh", nil }, func(error) (string, error) {
+		fallbackCalls++
+		return "stale", nil
+	})
+	if val2 != "fresh" || err != nil || fallbackCalls != 0 {
+		t.Fatalf("unexpected val %q err %v fallback calls %d", val2, err, fallbackCalls)
+	}
+
+	val2, err = DoValueOr(ctx, opts, failing, func(lastErr error) (string, error) {
+		if !errors.Is(lastErr, errFail) {
+			t.Fatalf("unexpected lastErr %v", lastErr)
+		}
+		return "stale", nil
+	})
+	if val2 != "stale" || err != nil {
+		t.Fatalf("unexpected val %q err %v", val2, err)
+	}
+
+	errFallback := errors.New("no stale value")
+	_, err = DoValueOr(ctx, opts, failing, func(lastErr error) (string, error) { return "", errFallback })
+	if !errors.Is(err, errFail) || !errors.Is(err, errFallback) {
+		t.Fatalf("expected both errors, got %v", err)
+	}
+
+	var retryErr error
+	_, err = DoValueOr(ctx, opts, failing, func(lastErr error) (string, error) {
+		retryErr = lastErr
+		return "", lastErr
+	})
+	if err.Error() != retryErr.Error() {
+		t.Fatalf("expected the retry error to be returned as is, got %v", err)
+	}
+}
+
+type testAttemptError struct{ attempt uint }
+
+func (e testAttemptError) Error() string { return fmt.Sprintf("attempt %d failed", e.attempt) }
+
+func TestTerminalErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	cancelled, cancelNow := context.WithCancel(ctx)
+	cancelNow()
+
+	failing := func(ctx context.Context, attempt uint) error { return testAttemptError{attempt} }
+	maxed := Options{MaxAttempts: 2, Delay: noDelay}
+	var group Group[int, int]
+	group.MaxAttempts, group.Delay = 2, noDelay
+
+	deadline, cancelDeadline := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancelDeadline()
+	errDown := errors.New("down")
+	breaker := Options{Delay: noDelay, Precondition: func(context.Context) error { return errDown }, PreconditionFailFast: true}
+
+	tests := []struct {
+		name     string
+		run      func() error
+		sentinel error
+		cause    bool
+	}{
+		{"Do max attempts", func() error { return DoCtx(ctx, maxed, failing) }, ErrMaxAttemptsReached, true},
+		{"Do collected max attempts", func() error {
+			opts := maxed
+			opts.CollectAttemptErrors = true
+			return DoCtx(ctx, opts, failing)
+		}, ErrMaxAttemptsReached, true},
+		{"Do cancelled", func() error { return DoCtx(cancelled, maxed, failing) }, nil, false},
+		{"Do deadline", func() error {
+			return DoCtx(deadline, Options{Delay: func(uint) time.Duration { return time.Millisecond }}, failing)
+		}, ErrBudgetExhausted, true},
+		{"Do breaker", func() error { return DoCtx(ctx, breaker, failing) }, ErrBreakerOpen, false},
+		{"DoValue max attempts", func() error {
+			_, err := DoValue(ctx, maxed, func() (int, error) { return 0, testAttemptError{1} })
+			return err
+		}, ErrMaxAttemptsReached, true},
+		{"RunRecord max attempts", func() error {
+			_, err := RunRecord(ctx, maxed, func() error { return testAttemptError{1} })
+			return err
+		}, ErrMaxAttemptsReached, true},
+		{"Group max attempts", func() error {
+			_, err := group.Do(ctx, 1, func(ctx context.Context) (int, error) { return 0, testAttemptError{1} })
+			return err
+		}, ErrMaxAttemptsReached, true},
+		{"Supervise run timeout", func() error {
+			supervised, stop := context.WithCancel(ctx)
+			defer stop()
+			var failure error
+			Supervise(supervised, time.Millisecond, func(err error) {
+				failure = err
+				stop()
+			}, func(ctx context.Context) error {
+				<-ctx.Done()
+				return testAttemptError{0}
+			})
+			return failure
+		}, ErrRunTimeout, true},
+	}
+	sentinels := []error{ErrMaxAttemptsReached, ErrBudgetExhausted, ErrBreakerOpen, ErrRunTimeout}
+	for _, tt := range tests {
+		err := tt.run()
+		for _, sentinel := range sentinels {
+			if got := errors.Is(err, sentinel); got != (sentinel == tt.sentinel) {
+				t.Fatalf("%s: errors.Is(%v, %v) == %t", tt.name, err, sentinel, got)
+			}
+		}
+		var cause testAttemptError
+		if got := errors.As(err, &cause); got != tt.cause {
+			t.Fatalf("%s: errors.As(%v) == %t", tt.name, err, got)
+		}
+	}
+	if err := DoCtx(ctx, breaker, failing); !errors.Is(err, errDown) {
+		t.Fatalf("expected the precondition error to be wrapped, got %v", err)
+	}
+}
+
+func TestDoInitialDelay(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	const initial = 20 * time.Millisecond
+	var delays []uint
+	var calls []time.Time
+	start := time.Now()
+	err := Do(ctx, Options{
+		InitialDelay: initial,
+		Delay: func(attempt uint) time.Duration {
+			delays = append(delays, attempt)
+			return time.Millisecond
+		},
+	}, func() error {
+		if calls = append(calls, time.Now()); len(calls) < 3 {
+			return errors.New("fail")
+		}
+		return nil
+	})
+	if err != nil || len(calls) != 3 {
+		t.Fatalf("unexpected err %v calls %d", err, len(calls))
+	}
+	if calls[0].Sub(start) >= initial {
+		t.Fatal("expected the first attempt to be immediate")
+	}
+	if gap := calls[1].Sub(calls[0]); gap < initial {
+		t.Fatalf("expected the first retry to wait InitialDelay, waited %v", gap)
+	}
+	if gap := calls[2].Sub(calls[1]); gap >= initial {
+		t.Fatalf("expected later retries to use Delay, waited %v", gap)
+	}
+
+	// A larger Delay(1) wins over InitialDelay.
+	calls = nil
+	err = Do(ctx, Options{InitialDelay: time.Millisecond, Delay: ConstantDelay(initial)}, func() error {
+		if calls = append(calls, time.Now()); len(calls) < 2 {
+			return errors.New("fail")
+		}
+		return nil
+	})
+	if err != nil || calls[1].Sub(calls[0]) < initial {
+		t.Fatalf("unexpected err %v gap %v", err, calls[1].Sub(calls[0]))
+	}
+
+	calls = nil
+	start = time.Now()
+	err = Do(ctx, Options{InitialDelay: initial, DelayBeforeFirstAttempt: true}, func() error {
+		calls = append(calls, time.Now())
+		return nil
+	})
+	if err != nil || calls[0].Sub(start) < initial {
+		t.Fatalf("expected a delay before the first attempt, err %v", err)
+	}
+
+	// Waiting past the deadline gives up once the context finishes instead of calling fn.
+	short, cancel := context.WithTimeout(ctx, initial)
+	defer cancel()
+	calls = nil
+	err = Do(short, Options{InitialDelay: time.Hour, DelayBeforeFirstAttempt: true}, func() error {
+		calls = append(calls, time.Now())
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || len(calls) != 0 {
+		t.Fatalf("unexpected err %v calls %d", err, len(calls))
+	}
+	short, cancel = context.WithTimeout(ctx, initial)
+	defer cancel()
+	calls = nil
+	err = Do(short, Options{InitialDelay: time.Hour}, func() error {
+		calls = append(calls, time.Now())
+		return errors.New("fail")
+	})
+	if err == nil || len(calls) != 1 || time.Since(calls[0]) > time.Second {
+		t.Fatalf("unexpected err %v calls %d", err, len(calls))
+	}
+}
+
+func TestDoAttemptTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var attemptCtxs []context.Context
+	err := DoCtx(ctx, Options{MaxAttempts: 3, AttemptTimeout: 10 * time.Millisecond, Delay: noDelay}, func(ctx context.Context, attempt uint) error {
+		attemptCtxs = append(attemptCtxs, ctx)
+		if _, ok := ctx.Deadline(); !ok {
+			t.Fatal("expected the attempt ctx to have a deadline")
+		}
+		if attempt < 2 {
+			// A hung call is abandoned after AttemptTimeout.
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	if err != nil || len(attemptCtxs) != 3 {
+		t.Fatalf("unexpected err %v attempts %d", err, len(attemptCtxs))
+	}
+	for i, attemptCtx := range attemptCtxs {
+		if attemptCtx.Err() == nil {
+			t.Fatalf("expected attempt %d ctx to be cancelled after fn returned", i)
+		}
+	}
+	if ctx.Err() != nil {
+		t.Fatal("expected the parent ctx to be unaffected")
+	}
+
+	err = DoCtx(ctx, Options{MaxAttempts: 2, Delay: noDelay}, func(attemptCtx context.Context, attempt uint) error {
+		if attemptCtx != ctx {
+			t.Fatal("expected ctx to be passed as is without AttemptTimeout")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDoPrecondition(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	errDown := errors.New("circuit open")
+	checks, calls := 0, 0
+	var fnAttempts []uint
+	precondition := func(context.Context) error {
+		if checks++; checks <= 2 {
+			return errDown
+		}
+		return nil
+	}
+	var retried []error
+	rec, err := RunRecord(ctx, Options{
+		Delay:        noDelay,
+		Precondition: precondition,
+		OnRetry:      func(attempt uint, err error) { retried = append(retried, err) },
+	}, func() error {
+		calls++
+		return nil
+	})
+	if err != nil || checks != 3 || calls != 1 || rec.Attempts != 3 {
+		t.Fatalf("unexpected err %v checks %d calls %d record %+v", err, checks, calls, rec)
+	}
+	if len(retried) != 2 || !errors.Is(retried[0], errDown) {
+		t.Fatalf("expected the precondition errors to be retried, got %v", retried)
+	}
+
+	checks = 0
+	err = DoCtx(ctx, Options{Delay: noDelay, MaxAttempts: 2, Precondition: precondition}, func(ctx context.Context, attempt uint) error {
+		fnAttempts = append(fnAttempts, attempt)
+		return nil
+	})
+	if !errors.Is(err, errDown) || !errors.Is(err, ErrMaxAttemptsReached) || len(fnAttempts) != 0 {
+		t.Fatalf("unexpected err %v fn attempts %v", err, fnAttempts)
+	}
+
+	checks, calls = 0, 0
+	rec, err = RunRecord(ctx, Options{Delay: noDelay, Precondition: precondition, PreconditionFailFast: true}, func() error {
+		calls++
+		return nil
+	})
+	if !errors.Is(err, errDown) || errors.Is(err, ErrMaxAttemptsReached) || checks != 1 || calls != 0 || rec.Attempts != 0 {
+		t.Fatalf("unexpected err %v checks %d calls %d record %+v", err, checks, calls, rec)
+	}
+}
@@ -0,0 +1,32 @@
+package retry
+
+import (
+	"context"
+	"sync"
+
+	"github.com/danlock/pkg/errors"
+)
+
+// ForEach calls fn for every item concurrently, retrying each item on its own according to opts like DoCtx.
+// At most limit items are in progress at once, so a mass retry doesn't overwhelm a downstream. Every item runs at once if limit is 0.
+// It waits for every item to finish and returns their final errors joined by errors.JoinIndexed,
+// so the attrs of each item's error are prefixed with its index, such as "branch2.retry_attempts". It returns nil if every item succeeded.
+func ForEach[T any](ctx context.Context, opts Options, limit int, items []T, fn func(ctx context.Context, item T) error) error {
+	if limit <= 0 {
+		limit = len(items)
+	}
+	sem := make(chan struct{}, limit)
+	errs := make([]error, len(items))
+	var wg sync.WaitGroup
+	for i, item := range items {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = DoCtx(ctx, opts, func(ctx context.Context, _ uint) error { return fn(ctx, item) })
+		}()
+	}
+	wg.Wait()
+	return errors.JoinIndexed(errs...)
+}
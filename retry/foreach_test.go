@@ -0,0 +1,73 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danlock/pkg/errors"
+)
+
+func TestForEach(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	const limit = 3
+	var inProgress, peak atomic.Int32
+	var mu sync.Mutex
+	calls := make(map[int]int)
+	errBad := errors.New("bad item")
+	items := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	err := ForEach(ctx, Options{MaxAttempts: 3, Delay: noDelay}, limit, items, func(ctx context.Context, item int) error {
+		n := inProgress.Add(1)
+		defer inProgress.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		calls[item]++
+		attempt := calls[item]
+		mu.Unlock()
+		switch {
+		case item%4 == 0:
+			return fmt.Errorf("item %d: %w", item, errBad)
+		case item%2 == 0 && attempt < 2:
+			return errors.New("flaky")
+		}
+		return nil
+	})
+
+	if peak.Load() > limit {
+		t.Fatalf("expected at most %d items at once, got %d", limit, peak.Load())
+	}
+	if !errors.Is(err, errBad) || !errors.Is(err, ErrMaxAttemptsReached) {
+		t.Fatalf("unexpected err %v", err)
+	}
+	meta := errors.UnwrapAttr(err)
+	if meta["branch0.retry_attempts"].Uint64() != 3 || meta["branch4.retry_attempts"].Uint64() != 3 {
+		t.Fatalf("expected the failed items' attrs, got %v", meta)
+	}
+	if _, ok := meta["branch2.retry_attempts"]; ok {
+		t.Fatalf("unexpected attrs for a successful item in %v", meta)
+	}
+	for _, item := range items {
+		want := map[int]int{0: 3, 2: 2}[item%4]
+		if want == 0 {
+			want = 1
+		}
+		if calls[item] != want {
+			t.Fatalf("item %d was called %d times, expected %d", item, calls[item], want)
+		}
+	}
+
+	if err := ForEach(ctx, Options{}, 0, items, func(context.Context, int) error { return nil }); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if err := ForEach(ctx, Options{}, 2, []string(nil), func(context.Context, string) error { return nil }); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
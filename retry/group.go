@@ -0,0 +1,102 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Group deduplicates concurrent retrying calls that share a key, similar to golang.org/x/sync/singleflight.
+// Callers of Do with the same key share one retrying execution of fn instead of each retrying on their own.
+// The zero value is ready to use.
+type Group[K comparable, T any] struct {
+	// Delay returns the backoff after a failed attempt. FibonacciDelay is used when nil.
+	Delay func(attempt uint) time.Duration
+	// MaxAttempts is the number of failed calls to fn before the shared execution gives up with ErrMaxAttemptsReached.
+	// The shared execution retries until it succeeds or every caller has left when MaxAttempts is 0.
+	MaxAttempts uint
+
+	mu    sync.Mutex
+	calls map[K]*groupCall[T]
+}
+
+type groupCall[T any] struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters uint
+	val     T
+	err     error
+}
+
+// Do calls fn until it succeeds, sharing the execution and its result with every concurrent caller using the same key.
+// Callers joining while fn is being retried receive the shared result or the last error from fn.
+// If ctx finishes before the shared execution, Do returns ctx.Err() without affecting the other callers.
+// The shared execution is only cancelled once every caller has left.
+// The ctx passed to fn keeps the values of the first caller's ctx, but not its cancellation.
+func (g *Group[K, T]) Do(ctx context.Context, key K, fn func(ctx context.Context) (T, error)) (T, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[K]*groupCall[T])
+	}
+	c, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &groupCall[T]{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = c
+		go g.run(callCtx, key, c, fn)
+	}
+	c.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c.waiters--
+	if c.waiters == 0 {
+		c.cancel()
+		g.forget(key, c)
+	}
+	var zero T
+	return zero, ctx.Err()
+}
+
+// run retries fn on behalf of every caller waiting on c.
+func (g *Group[K, T]) run(ctx context.Context, key K, c *groupCall[T], fn func(context.Context) (T, error)) {
+	defer c.cancel()
+	delay := g.Delay
+	if delay == nil {
+		delay = FibonacciDelay
+	}
+
+	for attempts := uint(1); ; attempts++ {
+		c.val, c.err = fn(ctx)
+		if c.err == nil {
+			break
+		}
+		if g.MaxAttempts > 0 && attempts >= g.MaxAttempts {
+			c.err = fmt.Errorf("%w: %w", ErrMaxAttemptsReached, c.err)
+			break
+		}
+		if !sleep(ctx, delay(attempts)) {
+			break
+		}
+	}
+
+	g.mu.Lock()
+	g.forget(key, c)
+	g.mu.Unlock()
+	close(c.done)
+}
+
+// forget removes c from the group so the next caller with key starts a new execution. g.mu must be held.
+func (g *Group[K, T]) forget(key K, c *groupCall[T]) {
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+}
@@ -0,0 +1,176 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroupShared(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var g Group[string, int]
+	g.Delay = func(uint) time.Duration { return time.Millisecond }
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		if calls.Add(1) < 3 {
+			return 0, errors.New("not yet")
+		}
+		<-release
+		return 7, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]int, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			val, err := g.Do(ctx, "key", fn)
+			if err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			results[i] = val
+		}(i)
+	}
+	for calls.Load() < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 shared calls, got %d", calls.Load())
+	}
+	for i, v := range results {
+		if v != 7 {
+			t.Fatalf("result %d == %d", i, v)
+		}
+	}
+	if len(g.calls) != 0 {
+		t.Fatalf("expected key cleanup, got %v", g.calls)
+	}
+
+	// A new call after completion starts a new execution.
+	if val, err := g.Do(ctx, "key", fn); err != nil || val != 7 || calls.Load() != 4 {
+		t.Fatalf("unexpected val %d err %v calls %d", val, err, calls.Load())
+	}
+}
+
+func TestGroupMaxAttempts(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	errFail := errors.New("fail")
+	g := Group[int, int]{MaxAttempts: 3, Delay: func(uint) time.Duration { return 0 }}
+	var calls atomic.Int32
+	_, err := g.Do(ctx, 1, func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		return 0, errFail
+	})
+	if !errors.Is(err, errFail) || calls.Load() != 3 {
+		t.Fatalf("unexpected err %v calls %d", err, calls.Load())
+	}
+}
+
+func TestGroupWaiterCancellation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var g Group[string, string]
+	started := make(chan struct{})
+	release := make(chan struct{})
+	sharedCancelled := make(chan struct{})
+	fn := func(ctx context.Context) (string, error) {
+		close(started)
+		select {
+		case <-release:
+			return "done", nil
+		case <-ctx.Done():
+			close(sharedCancelled)
+			return "", ctx.Err()
+		}
+	}
+
+	// The first waiter leaving doesn't cancel the shared execution.
+	leaverCtx, leave := context.WithCancel(ctx)
+	leaverErr := make(chan error)
+	go func() {
+		_, err := g.Do(leaverCtx, "key", fn)
+		leaverErr <- err
+	}()
+	<-started
+
+	stayerResult := make(chan string)
+	go func() {
+		val, err := g.Do(ctx, "key", fn)
+		if err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+		stayerResult <- val
+	}()
+	for waiters(&g, "key") != 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	leave()
+	if err := <-leaverErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	select {
+	case <-sharedCancelled:
+		t.Fatal("shared execution cancelled while a waiter remained")
+	default:
+	}
+	close(release)
+	if val := <-stayerResult; val != "done" {
+		t.Fatalf("unexpected result %q", val)
+	}
+}
+
+func TestGroupLastWaiterCancellation(t *testing.T) {
+	var g Group[string, string]
+	started := make(chan struct{})
+	sharedCancelled := make(chan struct{})
+	fn := func(ctx context.Context) (string, error) {
+		close(started)
+		<-ctx.Done()
+		close(sharedCancelled)
+		return "", ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() {
+		_, err := g.Do(ctx, "key", fn)
+		errs <- err
+	}()
+	<-started
+	cancel()
+
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	select {
+	case <-sharedCancelled:
+	case <-time.After(time.Second):
+		t.Fatal("shared execution wasn't cancelled after the last waiter left")
+	}
+	if waiters(&g, "key") != 0 {
+		t.Fatal("expected key cleanup after the last waiter left")
+	}
+}
+
+func waiters[K comparable, T any](g *Group[K, T], key K) uint {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if c, ok := g.calls[key]; ok {
+		return c.waiters
+	}
+	return 0
+}
@@ -2,9 +2,27 @@ package retry
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
 	"time"
 )
 
+var (
+	// ErrMaxAttemptsReached is returned by Do, DoValue and Group.Do when they give up after their max attempts,
+	// wrapping the error from the last attempt.
+	ErrMaxAttemptsReached = errors.New("retry: max attempts reached")
+	// ErrBudgetExhausted is returned by Do and DoValue when they give up because the context's deadline passed,
+	// wrapping the error from the last attempt, or ctx.Err() if fn was never called.
+	ErrBudgetExhausted = errors.New("retry: time budget exhausted")
+	// ErrBreakerOpen is returned by Do and DoValue when Options.Precondition fails with PreconditionFailFast set,
+	// wrapping the error from Precondition.
+	ErrBreakerOpen = errors.New("retry: breaker open")
+	// ErrRunTimeout is passed to Supervise's onFailure for runs that exceeded runTimeout, wrapping the error from fn.
+	ErrRunTimeout = errors.New("retry: run timed out")
+)
+
 // UntilDone repeatedly calls the provided function until the context finishes.
 func UntilDone(ctx context.Context, fn func()) {
 	for {
@@ -17,17 +35,107 @@ func UntilDone(ctx context.Context, fn func()) {
 	}
 }
 
-var fibonacciDurations = [...]time.Duration{
-	0, time.Second, time.Second, 2 * time.Second, 3 * time.Second, 5 * time.Second,
-	8 * time.Second, 13 * time.Second, 21 * time.Second, 34 * time.Second,
+// UntilDoneCtx is UntilDone, but passes ctx to fn so it can use context-aware operations, such as a timeout per call.
+func UntilDoneCtx(ctx context.Context, fn func(ctx context.Context)) {
+	UntilDoneCtxWithCount(ctx, func(ctx context.Context, _ uint) { fn(ctx) })
+}
+
+// UntilDoneCtxWithCount is UntilDoneCtx, but also passes the number of previous calls to fn, so it starts at 0.
+func UntilDoneCtxWithCount(ctx context.Context, fn func(ctx context.Context, attempt uint)) {
+	for attempt := uint(0); ctx.Err() == nil; attempt++ {
+		fn(ctx, attempt)
+	}
 }
 
-// FibonacciDelay provides a simple, default delay function that increases according to the Fibonacci sequence for 10 attempts.
+var fibonacciDelay = FibonacciDelayCapped(time.Second, 34*time.Second)
+
+// FibonacciDelay provides a simple, default delay function that increases according to the Fibonacci sequence in seconds.
+// It's capped at 34 seconds, reached at attempt 9. Use FibonacciDelayCapped for a different unit or cap.
 func FibonacciDelay(attempt uint) time.Duration {
-	if attempt < uint(len(fibonacciDurations)) {
-		return fibonacciDurations[attempt]
-	} else {
-		return fibonacciDurations[len(fibonacciDurations)-1]
+	return fibonacciDelay(attempt)
+}
+
+// FibonacciDelayCapped returns a delay function that increases according to the Fibonacci sequence in multiples of unit,
+// until it reaches maxDelay. Attempt 0 is 0, attempts 1 and 2 are unit, attempt 3 is 2*unit, and so on.
+// It returns 0 if unit or maxDelay isn't positive.
+func FibonacciDelayCapped(unit, maxDelay time.Duration) func(attempt uint) time.Duration {
+	return func(attempt uint) time.Duration {
+		if unit <= 0 || maxDelay <= 0 {
+			return 0
+		}
+		cur, next := time.Duration(0), unit
+		for range attempt {
+			if cur >= maxDelay {
+				return maxDelay
+			}
+			cur, next = next, cur+next
+			if next < cur {
+				// cur+next overflowed, so the following delay is past any cap.
+				next = math.MaxInt64
+			}
+		}
+		return min(cur, maxDelay)
+	}
+}
+
+// ConstantDelay returns a delay function that always waits d, apart from attempt 0 which is 0 like FibonacciDelay.
+func ConstantDelay(d time.Duration) func(attempt uint) time.Duration {
+	return func(attempt uint) time.Duration {
+		if attempt == 0 {
+			return 0
+		}
+		return d
+	}
+}
+
+// LinearDelay returns a delay function that waits step longer with every attempt, so attempt 0 is 0.
+func LinearDelay(step time.Duration) func(attempt uint) time.Duration {
+	return func(attempt uint) time.Duration {
+		return step * time.Duration(attempt)
+	}
+}
+
+// Exponential returns a delay function that starts at base and grows by multiplier with every attempt, capped at maxDelay.
+// Attempt 0 is 0 like FibonacciDelay, attempt 1 is base, attempt 2 is base*multiplier, and so on, saturating at maxDelay instead of overflowing.
+// A multiplier below 1 or NaN is treated as 1, and it returns 0 if base or maxDelay isn't positive.
+func Exponential(base, maxDelay time.Duration, multiplier float64) func(attempt uint) time.Duration {
+	if !(multiplier >= 1) {
+		multiplier = 1
+	}
+	return func(attempt uint) time.Duration {
+		if attempt == 0 || base <= 0 || maxDelay <= 0 {
+			return 0
+		}
+		d := float64(base) * math.Pow(multiplier, float64(attempt-1))
+		if d >= float64(maxDelay) {
+			return maxDelay
+		}
+		return time.Duration(d)
+	}
+}
+
+// CombineDelays returns a delay function that uses the delay function at the index chosen by selector for each attempt.
+// selector's result is clamped into the range of delays. For example, linear delays for the first 3 attempts then Fibonacci delays:
+//
+//	retry.CombineDelays(func(attempt uint) int { return int(attempt / 4) }, retry.LinearDelay(time.Second), retry.FibonacciDelay)
+func CombineDelays(selector func(attempt uint) int, delays ...func(attempt uint) time.Duration) func(attempt uint) time.Duration {
+	return func(attempt uint) time.Duration {
+		if len(delays) == 0 {
+			return 0
+		}
+		return delays[max(0, min(selector(attempt), len(delays)-1))](attempt)
+	}
+}
+
+// randFloat64 is the source of randomness for jitter, replaceable by tests.
+var randFloat64 = rand.Float64
+
+// FibonacciDelayJitter returns a delay function following FibonacciDelay, with each delay multiplied by a random factor within [1-frac, 1+frac].
+// frac is clamped into [0, 1). Attempt 0 is still 0, so successful calls are still retried immediately.
+func FibonacciDelayJitter(frac float64) func(attempt uint) time.Duration {
+	frac = max(0, min(frac, math.Nextafter(1, 0)))
+	return func(attempt uint) time.Duration {
+		return time.Duration(float64(FibonacciDelay(attempt)) * (1 + frac*(2*randFloat64()-1)))
 	}
 }
 
@@ -38,33 +146,175 @@ func WithBackoff(ctx context.Context, delay func(attempt uint) time.Duration, fn
 	WithMaxAttempts(ctx, 0, delay, fn)
 }
 
+// WithBackoffErr is WithBackoff for a fn returning an error, treating nil as success.
+// Once ctx finishes it returns ctx.Err(), wrapping the error from the last call to fn if it failed.
+func WithBackoffErr(ctx context.Context, delay func(attempt uint) time.Duration, fn func() error) error {
+	var lastErr error
+	WithBackoff(ctx, delay, func() bool {
+		lastErr = fn()
+		return lastErr == nil
+	})
+	if lastErr == nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("%w: %w", ctx.Err(), lastErr)
+}
+
+// WithBackoffResult is WithBackoff, but returns how the loop ended.
+// It only stops when ctx finishes, so the Result is useful for telling whether the last call succeeded before ctx finished.
+func WithBackoffResult(ctx context.Context, delay func(attempt uint) time.Duration, fn func() bool) Result {
+	return WithMaxAttemptsResult(ctx, 0, delay, fn)
+}
+
+// WithBackoffStoppable runs WithBackoff in a new goroutine, returning a stop function for ending it without owning ctx.
+// stop cancels any backoff in progress and waits for a call to fn in progress to return,
+// so fn is never called again once stop returns. stop can be called any number of times.
+func WithBackoffStoppable(ctx context.Context, delay func(attempt uint) time.Duration, fn func() bool) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		WithBackoff(ctx, delay, fn)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// WithBackoffCtx is WithBackoff, but fn is also given ctx and the current attempt.
+// attempt is the number of consecutive failed calls before this one, so it starts at 0 and resets to 0 after a success.
+// It's the same value delay was given for the backoff before this call.
+func WithBackoffCtx(ctx context.Context, delay func(attempt uint) time.Duration, fn func(ctx context.Context, attempt uint) bool) {
+	withMaxAttempts(ctx, 0, delay, fn)
+}
+
 // WithMaxAttempts repeatedly calls a function until the context finishes. The return value of the function is used to determine the backoff between retries.
 // If the function returned true, the backoff is delay(0). If false, the backoff is delay(number of failed attempts).
 // FibonacciDelay is used when delay is nil.
 // WithMaxAttempts also stops retrying after max attempt are reached as long as maxAttempts is greater than 0.
 func WithMaxAttempts(ctx context.Context, maxAttempts uint, delay func(attempt uint) time.Duration, fn func() bool) {
+	WithMaxAttemptsResult(ctx, maxAttempts, delay, fn)
+}
+
+// Poll calls fn every interval until the context finishes, like a time.Ticker that backs off while fn is failing.
+// After fn returns an error the wait is delay(number of consecutive errors) instead of interval,
+// and the next successful call resets the wait back to interval. Unlike WithBackoff, a success waits interval rather than delay(0).
+// fn is called immediately. FibonacciDelay is used when delay is nil.
+func Poll(ctx context.Context, interval time.Duration, delay func(attempt uint) time.Duration, fn func() error) {
 	if delay == nil {
 		delay = FibonacciDelay
 	}
 
-	var attempts uint
-	tmr := time.NewTimer(0)
-	defer tmr.Stop()
+	var failures uint
+	for wait := time.Duration(0); sleep(ctx, wait); {
+		if err := fn(); err != nil {
+			failures++
+			wait = delay(failures)
+		} else {
+			failures = 0
+			wait = interval
+		}
+	}
+}
+
+// StopReason is why a retry loop stopped.
+type StopReason uint8
+
+const (
+	// StopContextDone means the context finished.
+	StopContextDone StopReason = iota + 1
+	// StopMaxAttempts means the function failed too many times in a row.
+	StopMaxAttempts
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case StopContextDone:
+		return "context done"
+	case StopMaxAttempts:
+		return "max attempts"
+	default:
+		return fmt.Sprintf("StopReason(%d)", uint8(r))
+	}
+}
+
+// Result describes how a retry loop ended.
+type Result struct {
+	// Reason is why the loop stopped.
+	Reason StopReason
+	// Attempts is the number of consecutive failed calls when the loop stopped, including the last call.
+	Attempts uint
+	// Succeeded is true if the last call to the function succeeded.
+	Succeeded bool
+}
+
+// WithMaxAttemptsResult is WithMaxAttempts, but returns how the loop ended.
+// The Result distinguishes the context finishing from the attempts running out, even when both happened,
+// and reports whether the last call succeeded.
+func WithMaxAttemptsResult(ctx context.Context, maxAttempts uint, delay func(attempt uint) time.Duration, fn func() bool) Result {
+	return withMaxAttempts(ctx, maxAttempts, delay, func(context.Context, uint) bool { return fn() })
+}
+
+func withMaxAttempts(ctx context.Context, maxAttempts uint, delay func(attempt uint) time.Duration, fn func(ctx context.Context, attempt uint) bool) Result {
+	if delay == nil {
+		delay = FibonacciDelay
+	}
+
+	var res Result
+	// The timer is only created once fn needs a nonzero delay, since most calls never sleep.
+	var tmr *time.Timer
+	defer func() {
+		if tmr != nil {
+			tmr.Stop()
+		}
+	}()
 	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-tmr.C:
+		if ctx.Err() != nil {
+			res.Reason = StopContextDone
+			return res
 		}
 
-		if fn() {
-			attempts = 0
-		} else if maxAttempts > 0 && attempts >= maxAttempts {
-			return
+		res.Succeeded = fn(ctx, res.Attempts)
+		if res.Succeeded {
+			res.Attempts = 0
+		} else if maxAttempts > 0 && res.Attempts >= maxAttempts {
+			res.Attempts++
+			res.Reason = StopMaxAttempts
+			return res
+		} else {
+			res.Attempts++
+		}
+
+		d := delay(res.Attempts)
+		if d <= 0 {
+			continue
+		}
+		if tmr == nil {
+			tmr = time.NewTimer(d)
 		} else {
-			attempts++
+			tmr.Reset(d)
+		}
+		select {
+		case <-ctx.Done():
+			res.Reason = StopContextDone
+			return res
+		case <-tmr.C:
 		}
+	}
+}
 
-		tmr.Reset(delay(attempts))
+// sleep waits for d, returning false early if ctx finishes first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	tmr := time.NewTimer(d)
+	defer tmr.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-tmr.C:
+		return true
 	}
 }
@@ -2,18 +2,27 @@ package retry
 
 import (
 	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
 func TestUntilDone(t *testing.T) {
-	ctx, _ := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
 
 	count := 0
 
 	go UntilDone(ctx, func() {
 		count++
-		ctx, _ := context.WithTimeout(ctx, time.Millisecond)
+		ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+		defer cancel()
 		<-ctx.Done()
 	})
 
@@ -23,14 +32,49 @@ func TestUntilDone(t *testing.T) {
 	}
 }
 
+func TestUntilDoneCtx(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var attempts []uint
+	UntilDoneCtxWithCount(ctx, func(fnCtx context.Context, attempt uint) {
+		if fnCtx != ctx {
+			t.Fatal("expected fn to be given ctx")
+		}
+		if attempts = append(attempts, attempt); attempt == 2 {
+			cancel()
+		}
+	})
+	if !slices.Equal(attempts, []uint{0, 1, 2}) {
+		t.Fatalf("unexpected attempts %v", attempts)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	UntilDoneCtx(ctx, func(ctx context.Context) {
+		if calls++; calls == 3 {
+			cancel()
+		}
+		if ctx.Err() == nil && calls > 3 {
+			t.Fatal("expected no calls after ctx finished")
+		}
+	})
+	if calls != 3 {
+		t.Fatalf("unexpected calls %d", calls)
+	}
+}
+
 func TestWithMaxAttempts(t *testing.T) {
-	ctx, _ := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
 
 	count := 0
 
 	go WithMaxAttempts(ctx, 0, func(attempt uint) time.Duration { return 0 }, func() bool {
 		count++
-		ctx, _ := context.WithTimeout(ctx, time.Millisecond)
+		ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+		defer cancel()
 		<-ctx.Done()
 		return true
 	})
@@ -41,11 +85,13 @@ func TestWithMaxAttempts(t *testing.T) {
 	}
 
 	count = 0
-	ctx, _ = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
 
 	go WithMaxAttempts(ctx, 1, nil, func() bool {
 		count++
-		ctx, _ := context.WithTimeout(ctx, time.Millisecond)
+		ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+		defer cancel()
 		<-ctx.Done()
 		return false
 	})
@@ -55,3 +101,421 @@ func TestWithMaxAttempts(t *testing.T) {
 		t.Fatalf("unexpected count == %d", count)
 	}
 }
+
+func TestWithMaxAttemptsResult(t *testing.T) {
+	noDelay := func(attempt uint) time.Duration { return 0 }
+
+	count := 0
+	res := WithMaxAttemptsResult(context.Background(), 2, noDelay, func() bool {
+		count++
+		return false
+	})
+	if res != (Result{Reason: StopMaxAttempts, Attempts: 3}) || count != 3 {
+		t.Fatalf("unexpected result %+v count == %d", res, count)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	res = WithMaxAttemptsResult(ctx, 2, noDelay, func() bool {
+		cancel()
+		return true
+	})
+	if res != (Result{Reason: StopContextDone, Succeeded: true}) {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	res = WithMaxAttemptsResult(ctx, 0, func(uint) time.Duration { return time.Hour }, func() bool {
+		cancel()
+		return false
+	})
+	if res != (Result{Reason: StopContextDone, Attempts: 1}) {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	// Running out of attempts is reported even if the context finished as well.
+	count = 0
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	res = WithMaxAttemptsResult(ctx, 1, noDelay, func() bool {
+		if count++; count == 2 {
+			cancel()
+		}
+		return false
+	})
+	if res != (Result{Reason: StopMaxAttempts, Attempts: 2}) || ctx.Err() == nil {
+		t.Fatalf("unexpected result %+v ctx.Err() == %v", res, ctx.Err())
+	}
+}
+
+func TestPoll(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	const interval, backoff = time.Millisecond, 25 * time.Millisecond
+	errFail := errors.New("fail")
+	script := []error{nil, errFail, errFail, nil, errFail, nil}
+	var calls []time.Time
+	var delays []uint
+
+	Poll(ctx, interval, func(attempt uint) time.Duration {
+		delays = append(delays, attempt)
+		return time.Duration(attempt) * backoff
+	}, func() error {
+		calls = append(calls, time.Now())
+		if len(calls) == len(script) {
+			cancel()
+		}
+		return script[len(calls)-1]
+	})
+
+	if len(calls) != len(script) {
+		t.Fatalf("unexpected call count %d", len(calls))
+	}
+	if !slices.Equal(delays, []uint{1, 2, 1}) {
+		t.Fatalf("expected the backoff to reset after a success, got attempts %v", delays)
+	}
+	for i := 1; i < len(calls); i++ {
+		gap := calls[i].Sub(calls[i-1])
+		if script[i-1] == nil && (gap < interval || gap >= backoff) {
+			t.Fatalf("call %d waited %v after a success, expected the interval", i, gap)
+		} else if script[i-1] != nil && gap < backoff {
+			t.Fatalf("call %d waited %v after an error, expected a backoff", i, gap)
+		}
+	}
+}
+
+// stoppableCtx is a context whose Err can be set and reset without allocating, so a benchmark can reuse it.
+type stoppableCtx struct {
+	context.Context
+	stopped bool
+}
+
+func (c *stoppableCtx) Err() error {
+	if c.stopped {
+		return context.Canceled
+	}
+	return nil
+}
+
+func BenchmarkWithMaxAttemptsSuccessFirstTry(b *testing.B) {
+	ctx := &stoppableCtx{Context: context.Background()}
+	fn := func() bool {
+		ctx.stopped = true
+		return true
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ctx.stopped = false
+		WithMaxAttempts(ctx, 1, nil, fn)
+	}
+}
+
+func TestWithMaxAttemptsSuccessFirstTryAllocs(t *testing.T) {
+	ctx := &stoppableCtx{Context: context.Background()}
+	fn := func() bool {
+		ctx.stopped = true
+		return true
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		ctx.stopped = false
+		WithMaxAttempts(ctx, 1, nil, fn)
+	})
+	if allocs != 0 {
+		t.Fatalf("expected a first try success not to allocate a timer, got %v allocs", allocs)
+	}
+}
+
+func TestFibonacciDelayJitter(t *testing.T) {
+	defer func(f func() float64) { randFloat64 = f }(randFloat64)
+
+	tests := []struct {
+		frac, rand float64
+		attempt    uint
+		want       time.Duration
+	}{
+		{0.5, 0, 3, time.Second},
+		{0.5, 0.5, 3, 2 * time.Second},
+		{0.5, 0.75, 3, 2500 * time.Millisecond},
+		{0.1, 0.999, 0, 0},
+		{-1, 0, 5, 5 * time.Second},
+		{0, 0.9, 5, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		randFloat64 = func() float64 { return tt.rand }
+		if got := FibonacciDelayJitter(tt.frac)(tt.attempt); got != tt.want {
+			t.Fatalf("FibonacciDelayJitter(%v)(%d) with rand %v == %v", tt.frac, tt.attempt, tt.rand, got)
+		}
+	}
+
+	// frac is clamped below 1, so a delay is never more than doubled.
+	randFloat64 = func() float64 { return 0.999 }
+	if got := FibonacciDelayJitter(2)(1); got >= 2*time.Second {
+		t.Fatalf("unexpected delay %v", got)
+	}
+
+	randFloat64 = rand.Float64
+	delay := FibonacciDelayJitter(0.2)
+	for attempt := uint(1); attempt < 20; attempt++ {
+		base := FibonacciDelay(attempt)
+		if got := delay(attempt); got < base*8/10 || got > base*12/10 {
+			t.Fatalf("delay(%d) == %v outside of 20%% of %v", attempt, got, base)
+		}
+	}
+}
+
+func TestWithBackoffCtx(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	script := []bool{false, false, true, false, true}
+	var fnAttempts, delayAttempts []uint
+	WithBackoffCtx(ctx, func(attempt uint) time.Duration {
+		delayAttempts = append(delayAttempts, attempt)
+		return 0
+	}, func(fnCtx context.Context, attempt uint) bool {
+		if fnCtx != ctx {
+			t.Fatal("expected fn to be given ctx")
+		}
+		fnAttempts = append(fnAttempts, attempt)
+		if len(fnAttempts) == len(script) {
+			cancel()
+		}
+		return script[len(fnAttempts)-1]
+	})
+
+	if !slices.Equal(fnAttempts, []uint{0, 1, 2, 0, 1}) {
+		t.Fatalf("unexpected fn attempts %v", fnAttempts)
+	}
+	// Every call is given the attempt the delay before it was given.
+	if !slices.Equal(fnAttempts[1:], delayAttempts[:len(fnAttempts)-1]) {
+		t.Fatalf("fn attempts %v don't match delay attempts %v", fnAttempts, delayAttempts)
+	}
+}
+
+func TestWithBackoffErr(t *testing.T) {
+	noDelay := func(uint) time.Duration { return 0 }
+	errFail := errors.New("fail")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := WithBackoffErr(ctx, noDelay, func() error {
+		if calls++; calls == 3 {
+			cancel()
+		}
+		return errFail
+	})
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, errFail) || calls != 3 {
+		t.Fatalf("unexpected err %v calls %d", err, calls)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	calls = 0
+	err = WithBackoffErr(ctx, noDelay, func() error {
+		if calls++; calls < 3 {
+			return errFail
+		}
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || errors.Is(err, errFail) {
+		t.Fatalf("expected only the ctx error after a success, got %v", err)
+	}
+}
+
+func TestWithBackoffResult(t *testing.T) {
+	for _, succeed := range []bool{true, false} {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		res := WithBackoffResult(ctx, noDelay, func() bool {
+			if calls++; calls == 3 {
+				cancel()
+				return succeed
+			}
+			return !succeed
+		})
+		cancel()
+
+		want := Result{Reason: StopContextDone, Succeeded: succeed}
+		if !succeed {
+			want.Attempts = 1
+		}
+		if res != want {
+			t.Fatalf("unexpected result %+v", res)
+		}
+		if res.Reason.String() != "context done" {
+			t.Fatalf("unexpected reason %v", res.Reason)
+		}
+	}
+
+	res := WithMaxAttemptsResult(context.Background(), 1, noDelay, func() bool { return false })
+	if res.Reason != StopMaxAttempts || res.Reason.String() != "max attempts" {
+		t.Fatalf("unexpected reason %v", res.Reason)
+	}
+	if got := StopReason(0).String(); got != "StopReason(0)" {
+		t.Fatalf("unexpected String %q", got)
+	}
+}
+
+func TestWithBackoffStoppable(t *testing.T) {
+	var calls atomic.Int64
+	stop := WithBackoffStoppable(context.Background(), noDelay, func() bool {
+		calls.Add(1)
+		return true
+	})
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for calls.Load() < 100 {
+				runtime.Gosched()
+			}
+			stop()
+		}()
+	}
+	wg.Wait()
+	stopped := calls.Load()
+	time.Sleep(5 * time.Millisecond)
+	if calls.Load() != stopped {
+		t.Fatalf("fn called %d times after stop returned", calls.Load()-stopped)
+	}
+
+	// stop also ends a backoff in progress.
+	stop = WithBackoffStoppable(context.Background(), func(uint) time.Duration { return time.Hour }, func() bool { return false })
+	start := time.Now()
+	stop()
+	if time.Since(start) > time.Second {
+		t.Fatal("stop waited for the backoff")
+	}
+}
+
+func TestDelays(t *testing.T) {
+	constant, linear := ConstantDelay(time.Second), LinearDelay(2*time.Second)
+	combined := CombineDelays(func(attempt uint) int { return int(attempt / 3) }, linear, constant)
+	// The selector is clamped into the range of delays.
+	clamped := CombineDelays(func(attempt uint) int { return int(attempt) - 1 }, linear, constant)
+
+	tests := []struct {
+		attempt                                   uint
+		constant, linear, combined, clampedToEnds time.Duration
+	}{
+		{0, 0, 0, 0, 0},
+		{1, time.Second, 2 * time.Second, 2 * time.Second, 2 * time.Second},
+		{2, time.Second, 4 * time.Second, 4 * time.Second, time.Second},
+		{3, time.Second, 6 * time.Second, time.Second, time.Second},
+		{10, time.Second, 20 * time.Second, time.Second, time.Second},
+	}
+	for _, tt := range tests {
+		if got := constant(tt.attempt); got != tt.constant {
+			t.Fatalf("ConstantDelay(%d) == %v", tt.attempt, got)
+		}
+		if got := linear(tt.attempt); got != tt.linear {
+			t.Fatalf("LinearDelay(%d) == %v", tt.attempt, got)
+		}
+		if got := combined(tt.attempt); got != tt.combined {
+			t.Fatalf("CombineDelays(%d) == %v", tt.attempt, got)
+		}
+		if got := clamped(tt.attempt); got != tt.clampedToEnds {
+			t.Fatalf("clamped CombineDelays(%d) == %v", tt.attempt, got)
+		}
+	}
+	if got := CombineDelays(func(uint) int { return 0 })(5); got != 0 {
+		t.Fatalf("expected 0 without any delays, got %v", got)
+	}
+}
+
+func TestExponential(t *testing.T) {
+	delay := Exponential(100*time.Millisecond, 2*time.Second, 2)
+	tests := []struct {
+		attempt uint
+		want    time.Duration
+	}{
+		{0, 0},
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, 1600 * time.Millisecond},
+		{6, 2 * time.Second},
+		{100, 2 * time.Second},
+		{math.MaxUint, 2 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := delay(tt.attempt); got != tt.want {
+			t.Fatalf("Exponential(%d) == %v, expected %v", tt.attempt, got, tt.want)
+		}
+	}
+
+	if got := Exponential(time.Second, math.MaxInt64, 10)(1000); got != math.MaxInt64 {
+		t.Fatalf("expected saturation at the cap, got %v", got)
+	}
+	if got := Exponential(time.Second, time.Minute, 0.5)(5); got != time.Second {
+		t.Fatalf("expected a multiplier below 1 to be raised to 1, got %v", got)
+	}
+	for _, tt := range []struct {
+		multiplier float64
+		attempt    uint
+		want       time.Duration
+	}{
+		{math.NaN(), 5, time.Second},
+		{math.Inf(-1), 5, time.Second},
+		{math.Inf(1), 1, time.Second},
+		{math.Inf(1), 2, time.Minute},
+		{math.Inf(1), math.MaxUint, time.Minute},
+	} {
+		if got := Exponential(time.Second, time.Minute, tt.multiplier)(tt.attempt); got != tt.want {
+			t.Fatalf("Exponential with multiplier %v (%d) == %v, expected %v", tt.multiplier, tt.attempt, got, tt.want)
+		}
+	}
+	if got := Exponential(0, time.Minute, 2)(5); got != 0 {
+		t.Fatalf("expected 0 without a base, got %v", got)
+	}
+}
+
+func TestFibonacciDelayCapped(t *testing.T) {
+	// FibonacciDelay kept the values of its original lookup table.
+	table := []time.Duration{
+		0, time.Second, time.Second, 2 * time.Second, 3 * time.Second, 5 * time.Second,
+		8 * time.Second, 13 * time.Second, 21 * time.Second, 34 * time.Second,
+	}
+	for attempt, want := range table {
+		if got := FibonacciDelay(uint(attempt)); got != want {
+			t.Fatalf("FibonacciDelay(%d) == %v, expected %v", attempt, got, want)
+		}
+	}
+	for _, attempt := range []uint{10, 11, 100, math.MaxUint} {
+		if got := FibonacciDelay(attempt); got != 34*time.Second {
+			t.Fatalf("FibonacciDelay(%d) == %v, expected the 34s cap", attempt, got)
+		}
+	}
+
+	nightly := FibonacciDelayCapped(time.Second, 10*time.Minute)
+	if got := nightly(14); got != 377*time.Second {
+		t.Fatalf("expected growth past 34s, got %v", got)
+	}
+
+	caps := []time.Duration{time.Millisecond, time.Minute, time.Hour, math.MaxInt64}
+	units := []time.Duration{time.Nanosecond, time.Second, time.Hour, math.MaxInt64 / 2}
+	for _, unit := range units {
+		for _, maxDelay := range caps {
+			delay := FibonacciDelayCapped(unit, maxDelay)
+			prev := time.Duration(0)
+			for attempt := range uint(200) {
+				d := delay(attempt)
+				if d < prev || d > maxDelay {
+					t.Fatalf("FibonacciDelayCapped(%v, %v)(%d) == %v after %v", unit, maxDelay, attempt, d, prev)
+				}
+				prev = d
+			}
+			if got := delay(math.MaxUint); got != maxDelay {
+				t.Fatalf("FibonacciDelayCapped(%v, %v)(MaxUint) == %v", unit, maxDelay, got)
+			}
+		}
+	}
+
+	if got := FibonacciDelayCapped(0, time.Second)(math.MaxUint); got != 0 {
+		t.Fatalf("expected 0 for a zero unit, got %v", got)
+	}
+}
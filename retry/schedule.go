@@ -0,0 +1,34 @@
+package retry
+
+import "time"
+
+// OutsideWindow returns an Options.AllowAttempt that rejects attempts during a daily window, such as nightly maintenance.
+// Only the clock times of start and end are used, interpreted in loc. The window starts at start and ends before end,
+// and it crosses midnight when end is earlier than start. Attempts within the window resume once it ends.
+// Clock times skipped or repeated by daylight saving changes are normalized by time.Date.
+func OutsideWindow(start, end time.Time, loc *time.Location) func(now time.Time) (ok bool, resumeAt time.Time) {
+	startHour, startMin, startSec := start.Clock()
+	endHour, endMin, endSec := end.Clock()
+	return func(now time.Time) (bool, time.Time) {
+		now = now.In(loc)
+		year, month, day := now.Date()
+		todayStart := time.Date(year, month, day, startHour, startMin, startSec, 0, loc)
+		todayEnd := time.Date(year, month, day, endHour, endMin, endSec, 0, loc)
+
+		if todayEnd.After(todayStart) {
+			if !now.Before(todayStart) && now.Before(todayEnd) {
+				return false, todayEnd
+			}
+			return true, time.Time{}
+		}
+
+		// The window crosses midnight, so it's either today's window ending tomorrow or yesterday's window ending today.
+		if !now.Before(todayStart) {
+			return false, time.Date(year, month, day+1, endHour, endMin, endSec, 0, loc)
+		}
+		if now.Before(todayEnd) {
+			return false, todayEnd
+		}
+		return true, time.Time{}
+	}
+}
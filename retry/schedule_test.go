@@ -0,0 +1,99 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestOutsideWindow(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := func(hour, min int) time.Time { return time.Date(0, 1, 1, hour, min, 0, 0, time.UTC) }
+	at := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, ny)
+	}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		now        time.Time
+		ok         bool
+		resumeAt   time.Time
+	}{
+		{"before", clock(1, 0), clock(3, 0), at(2024, 6, 1, 0, 59), true, time.Time{}},
+		{"start", clock(1, 0), clock(3, 0), at(2024, 6, 1, 1, 0), false, at(2024, 6, 1, 3, 0)},
+		{"end", clock(1, 0), clock(3, 0), at(2024, 6, 1, 3, 0), true, time.Time{}},
+		{"midnight before", clock(23, 0), clock(2, 0), at(2024, 6, 1, 23, 30), false, at(2024, 6, 2, 2, 0)},
+		{"midnight after", clock(23, 0), clock(2, 0), at(2024, 6, 2, 1, 30), false, at(2024, 6, 2, 2, 0)},
+		{"midnight outside", clock(23, 0), clock(2, 0), at(2024, 6, 2, 12, 0), true, time.Time{}},
+		{"month end", clock(23, 0), clock(2, 0), at(2024, 6, 30, 23, 0), false, at(2024, 7, 1, 2, 0)},
+		{"other location", clock(1, 0), clock(3, 0), time.Date(2024, 6, 1, 6, 0, 0, 0, time.UTC), false, at(2024, 6, 1, 3, 0)},
+	}
+	for _, tt := range tests {
+		ok, resumeAt := OutsideWindow(tt.start, tt.end, ny)(tt.now)
+		if ok != tt.ok || !resumeAt.Equal(tt.resumeAt) {
+			t.Fatalf("%s: OutsideWindow(%v) == %t, %v", tt.name, tt.now, ok, resumeAt)
+		}
+	}
+
+	// The clocks spring forward from 2:00 to 3:00, so the window ends 30 minutes after 1:30.
+	now := at(2024, 3, 10, 1, 30)
+	if ok, resumeAt := OutsideWindow(clock(1, 0), clock(3, 0), ny)(now); ok || resumeAt.Sub(now) != 30*time.Minute {
+		t.Fatalf("unexpected %t, resume in %v", ok, resumeAt.Sub(now))
+	}
+	// The clocks fall back from 2:00 to 1:00, so the window ends 90 minutes after the first 1:30.
+	now = time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC)
+	if ok, resumeAt := OutsideWindow(clock(1, 0), clock(2, 0), ny)(now); ok || resumeAt.Sub(now) != 90*time.Minute {
+		t.Fatalf("unexpected %t, resume in %v", ok, resumeAt.Sub(now))
+	}
+}
+
+func TestDoAllowAttempt(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	checks, calls := 0, 0
+	rec, err := RunRecord(ctx, Options{
+		MaxAttempts: 2,
+		Delay:       noDelay,
+		AllowAttempt: func(now time.Time) (bool, time.Time) {
+			checks++
+			return checks > 3, now.Add(time.Millisecond)
+		},
+	}, func() error {
+		if calls++; calls < 2 {
+			return errors.New("fail")
+		}
+		return nil
+	})
+	if err != nil || !rec.Succeeded || rec.Attempts != 2 || checks != 5 {
+		t.Fatalf("unexpected record %+v err %v checks %d", rec, err, checks)
+	}
+
+	// A rejected attempt waits for the context instead of calling fn.
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	calls = 0
+	rec, err = RunRecord(ctx, Options{AllowAttempt: func(now time.Time) (bool, time.Time) { return false, now.Add(time.Hour) }},
+		func() error { calls++; return nil })
+	if !errors.Is(err, context.DeadlineExceeded) || calls != 0 || rec.Attempts != 0 {
+		t.Fatalf("unexpected record %+v err %v calls %d", rec, err, calls)
+	}
+
+	// A resumeAt that's zero or past still waits between checks instead of spinning.
+	for _, resumeAt := range []time.Time{{}, time.Now().Add(-time.Hour)} {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		checks = 0
+		_, err = RunRecord(ctx, Options{AllowAttempt: func(time.Time) (bool, time.Time) { checks++; return false, resumeAt }},
+			func() error { return nil })
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) || checks > 6 {
+			t.Fatalf("expected a few checks for resumeAt %v, got %d with err %v", resumeAt, checks, err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Supervise calls fn in a loop until the context finishes, like UntilDone for long running work such as a consumer loop.
+// Each run gets a ctx that times out after runTimeout as long as runTimeout is greater than 0.
+// Errors returned by fn are passed to onFailure, including runs that exceeded runTimeout, which wrap ErrRunTimeout.
+// Failing runs back off according to FibonacciDelay, while a successful run resets the backoff and runs fn again immediately.
+// Runs ended by ctx finishing are not reported to onFailure.
+func Supervise(ctx context.Context, runTimeout time.Duration, onFailure func(error), fn func(ctx context.Context) error) {
+	Poll(ctx, 0, FibonacciDelay, func() error {
+		err := superviseRun(ctx, runTimeout, fn)
+		if err != nil && ctx.Err() == nil && onFailure != nil {
+			onFailure(err)
+		}
+		return err
+	})
+}
+
+func superviseRun(ctx context.Context, runTimeout time.Duration, fn func(ctx context.Context) error) error {
+	if runTimeout <= 0 {
+		return fn(ctx)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, runTimeout)
+	defer cancel()
+	err := fn(runCtx)
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		// fn may have ignored its ctx and returned nil anyway, but the run still took too long.
+		if err == nil {
+			err = runCtx.Err()
+		}
+		return fmt.Errorf("%w after %v: %w", ErrRunTimeout, runTimeout, err)
+	}
+	return err
+}
+
+// foreverDelay is the backoff used by Forever, replaceable by tests.
+var foreverDelay = FibonacciDelay
+
+// Forever calls fn in a loop until the context finishes, keeping a background worker alive no matter what.
+// A panic within fn is recovered and passed to onPanic if it's not nil, then treated like fn returning an error.
+// Errors and panics back off according to FibonacciDelay, while a successful run calls fn again immediately.
+func Forever(ctx context.Context, fn func() error, onPanic func(any)) {
+	Poll(ctx, 0, foreverDelay, func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				if onPanic != nil {
+					onPanic(r)
+				}
+				err = fmt.Errorf("retry.Forever recovered panic: %v", r)
+			}
+		}()
+		return fn()
+	})
+}
@@ -0,0 +1,116 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSupervise(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var failures []error
+	runs := 0
+	Supervise(ctx, 5*time.Millisecond, func(err error) {
+		failures = append(failures, err)
+		cancel()
+	}, func(ctx context.Context) error {
+		// Successful runs repeat immediately, then the run times out.
+		if runs++; runs < 3 {
+			return nil
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if runs != 3 || len(failures) != 1 || !errors.Is(failures[0], context.DeadlineExceeded) {
+		t.Fatalf("unexpected runs %d failures %v", runs, failures)
+	}
+
+	// A run that ignores its ctx is still reported once it exceeds the timeout.
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	failures = nil
+	Supervise(ctx, time.Millisecond, func(err error) {
+		failures = append(failures, err)
+		cancel()
+	}, func(ctx context.Context) error {
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	})
+	if len(failures) != 1 || !errors.Is(failures[0], context.DeadlineExceeded) {
+		t.Fatalf("unexpected failures %v", failures)
+	}
+
+	errFail := errors.New("fail")
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	failures = nil
+	Supervise(ctx, 0, func(err error) {
+		failures = append(failures, err)
+		cancel()
+	}, func(ctx context.Context) error { return errFail })
+	if len(failures) != 1 || failures[0] != errFail {
+		t.Fatalf("unexpected failures %v", failures)
+	}
+
+	// Shutting down isn't reported as a failure.
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	failures = nil
+	Supervise(ctx, time.Second, func(err error) { failures = append(failures, err) }, func(ctx context.Context) error {
+		cancel()
+		return ctx.Err()
+	})
+	if len(failures) != 0 {
+		t.Fatalf("unexpected failures %v", failures)
+	}
+}
+
+func TestForever(t *testing.T) {
+	defer func(delay func(uint) time.Duration) { foreverDelay = delay }(foreverDelay)
+	var delays []uint
+	foreverDelay = func(attempt uint) time.Duration {
+		delays = append(delays, attempt)
+		return time.Millisecond
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var panics []any
+	runs := 0
+	Forever(ctx, func() error {
+		switch runs++; runs {
+		case 1:
+			panic("boom")
+		case 2:
+			return errors.New("fail")
+		case 3:
+			var m map[string]int
+			m["nil"]++
+		case 5:
+			cancel()
+		}
+		return nil
+	}, func(r any) { panics = append(panics, r) })
+
+	if runs != 5 || len(panics) != 2 || panics[0] != "boom" {
+		t.Fatalf("unexpected runs %d panics %v", runs, panics)
+	}
+	if _, ok := panics[1].(error); !ok {
+		t.Fatalf("expected a runtime error, got %v", panics[1])
+	}
+	// Panics back off like errors do, so the failures in a row back off longer.
+	if len(delays) != 3 || delays[2] != 3 {
+		t.Fatalf("unexpected delays %v", delays)
+	}
+
+	// A nil onPanic still recovers.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	Forever(ctx, func() error {
+		defer cancel()
+		panic("boom")
+	}, nil)
+}
@@ -0,0 +1,60 @@
+package set
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+)
+
+// Bag is a multiset, counting how many times each value was added.
+type Bag[T comparable] map[T]int
+
+// Add increments the count of each of vals and returns the Bag.
+func (b Bag[T]) Add(vals ...T) Bag[T] {
+	for _, v := range vals {
+		b[v]++
+	}
+	return b
+}
+
+// Count returns how many times v is in the Bag.
+func (b Bag[T]) Count(v T) int {
+	return b[v]
+}
+
+// Remove decrements the count of each of vals and returns the Bag. A value is deleted once its count reaches 0.
+func (b Bag[T]) Remove(vals ...T) Bag[T] {
+	for _, v := range vals {
+		if b[v] <= 1 {
+			delete(b, v)
+		} else {
+			b[v]--
+		}
+	}
+	return b
+}
+
+// Set returns a new Set of the values in the Bag.
+func (b Bag[T]) Set() Set[T] {
+	return FromSeq(maps.Keys(b))
+}
+
+// TopN returns up to n values of b with the highest counts, from most to least frequent.
+// Values with the same count are in ascending order so the result is deterministic.
+func TopN[T cmp.Ordered](b Bag[T], n int) []T {
+	vals := slices.SortedFunc(maps.Keys(b), func(x, y T) int {
+		return cmp.Or(cmp.Compare(b[y], b[x]), cmp.Compare(x, y))
+	})
+	return vals[:max(0, min(n, len(vals)))]
+}
+
+// Most returns the most frequent value in b and its count, breaking ties with the smallest value.
+// It returns the zero value and 0 if b is empty.
+func Most[T cmp.Ordered](b Bag[T]) (v T, count int) {
+	for val, c := range b {
+		if c > count || (c == count && val < v) {
+			v, count = val, c
+		}
+	}
+	return v, count
+}
@@ -0,0 +1,54 @@
+package set
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBag(t *testing.T) {
+	b := make(Bag[string]).Add(strings.Fields("the cat saw the other cat and the dog")...)
+	if b.Count("the") != 3 || b.Count("cat") != 2 || b.Count("dog") != 1 || b.Count("bird") != 0 {
+		t.Fatalf("unexpected counts %v", b)
+	}
+
+	b.Remove("the", "dog", "bird")
+	if b.Count("the") != 2 || b.Count("dog") != 0 {
+		t.Fatalf("unexpected counts %v", b)
+	}
+	if _, ok := b["dog"]; ok {
+		t.Fatal("expected dog to be deleted at 0")
+	}
+	if _, ok := b["bird"]; ok {
+		t.Fatal("expected removing a missing value to be a no-op")
+	}
+
+	s := b.Set()
+	if len(s) != len(b) || !s.HasAll(slices.Values([]string{"the", "cat", "saw", "other", "and"})) {
+		t.Fatalf("unexpected set %v", s)
+	}
+}
+
+func TestTopN(t *testing.T) {
+	b := make(Bag[string]).Add("b", "a", "c", "c", "d", "d", "d", "a")
+	if got := TopN(b, 3); !slices.Equal(got, []string{"d", "a", "c"}) {
+		t.Fatalf("unexpected TopN %v", got)
+	}
+	if got := TopN(b, 10); !slices.Equal(got, []string{"d", "a", "c", "b"}) {
+		t.Fatalf("unexpected TopN %v", got)
+	}
+	if got := TopN(b, -1); len(got) != 0 {
+		t.Fatalf("unexpected TopN %v", got)
+	}
+
+	if v, count := Most(b); v != "d" || count != 3 {
+		t.Fatalf("unexpected Most %q %d", v, count)
+	}
+	b.Remove("d")
+	if v, count := Most(b); v != "a" || count != 2 {
+		t.Fatalf("expected the smallest of the tied values, got %q %d", v, count)
+	}
+	if v, count := Most(Bag[int]{}); v != 0 || count != 0 {
+		t.Fatalf("unexpected Most %d %d", v, count)
+	}
+}
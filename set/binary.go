@@ -0,0 +1,223 @@
+package set
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"hash/maphash"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/danlock/pkg/errors"
+)
+
+// The first byte of the binary encoding of a Set says how the values are encoded.
+// These are part of the format, so they can't change.
+const (
+	// binaryFixedInt is followed by a byte with the size of each value, a uvarint count,
+	// then every value as a little endian integer of that size in ascending order of their bits.
+	binaryFixedInt byte = 1
+	// binaryGob is followed by the values encoded with encoding/gob as a slice.
+	binaryGob byte = 2
+)
+
+// AppendBinary appends the binary encoding of the Set to b, like encoding.BinaryAppender.
+// Values of an integer kind are encoded compactly as fixed size integers, with int and uint always taking 8 bytes.
+// Other values are encoded with encoding/gob. Integers are sorted, as are strings and floats within the gob,
+// so equal sets encode to the same bytes.
+func (s Set[T]) AppendBinary(b []byte) ([]byte, error) {
+	if size, ok := fixedIntSize[T](); ok {
+		b = append(b, binaryFixedInt, byte(size))
+		b = binary.AppendUvarint(b, uint64(len(s)))
+		bits := appendIntBits(make([]uint64, 0, len(s)), s)
+		slices.Sort(bits)
+		for _, u := range bits {
+			for i := range size {
+				b = append(b, byte(u>>(8*i)))
+			}
+		}
+		return b, nil
+	}
+
+	vals := slices.Collect(maps.Keys(s))
+	sortValues(vals)
+	buf := bytes.NewBuffer(append(b, binaryGob))
+	if err := gob.NewEncoder(buf).Encode(vals); err != nil {
+		return b, errors.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler with AppendBinary, so encoding/gob uses it too.
+func (s Set[T]) MarshalBinary() ([]byte, error) {
+	return s.AppendBinary(nil)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler for the encoding of AppendBinary, replacing the previous contents of the Set.
+// Integers must have been encoded from a type of the same size.
+func (s *Set[T]) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("empty data")
+	}
+	var vals []T
+	switch data[0] {
+	case binaryFixedInt:
+		size, ok := fixedIntSize[T]()
+		if !ok || len(data) < 2 {
+			return errors.Errorf("can't decode integers into %T", vals)
+		}
+		if int(data[1]) != size {
+			return errors.Errorf("can't decode %d byte integers into %T", data[1], vals)
+		}
+		count, n := binary.Uvarint(data[2:])
+		data = data[2+max(n, 0):]
+		if n <= 0 || count != uint64(len(data)/size) || len(data)%size != 0 {
+			return errors.New("invalid integer count")
+		}
+		vals = make([]T, count)
+		decodeInts(vals, data, size)
+	case binaryGob:
+		if err := gob.NewDecoder(bytes.NewReader(data[1:])).Decode(&vals); err != nil {
+			return errors.Wrap(err)
+		}
+	default:
+		return errors.Errorf("unknown format %d", data[0])
+	}
+
+	if *s == nil {
+		*s = make(Set[T], len(vals))
+	}
+	clear(*s)
+	s.Add(vals...)
+	return nil
+}
+
+// Hash returns a digest of the Set that doesn't depend on the order of its values, such as to cheaply check whether sets have changed.
+// It sums the maphash.Comparable of every value, so hashes can only be compared within the same process with the same seed,
+// not between processes or replicas. Like any hash, different sets can collide.
+func (s Set[T]) Hash(seed maphash.Seed) uint64 {
+	var sum uint64
+	for v := range s {
+		sum += maphash.Comparable(seed, v)
+	}
+	return sum
+}
+
+// fixedIntSize returns the size of T for the binary encoding if it has an integer kind.
+func fixedIntSize[T any]() (int, bool) {
+	switch reflect.TypeFor[T]().Kind() {
+	case reflect.Int8, reflect.Uint8:
+		return 1, true
+	case reflect.Int16, reflect.Uint16:
+		return 2, true
+	case reflect.Int32, reflect.Uint32:
+		return 4, true
+	case reflect.Int, reflect.Uint, reflect.Int64, reflect.Uint64:
+		return 8, true
+	default:
+		return 0, false
+	}
+}
+
+// integer is the types with an integer kind, which the binary encoding stores as fixed size integers.
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// appendIntBits appends the bits of every value of s to bits. T must have an integer kind.
+// The builtin integer types are switched on once for the whole Set, leaving reflect for named integer types.
+func appendIntBits[T comparable](bits []uint64, s Set[T]) []uint64 {
+	switch s := any(s).(type) {
+	case Set[int]:
+		return appendBitsOf(bits, s)
+	case Set[int8]:
+		return appendBitsOf(bits, s)
+	case Set[int16]:
+		return appendBitsOf(bits, s)
+	case Set[int32]:
+		return appendBitsOf(bits, s)
+	case Set[int64]:
+		return appendBitsOf(bits, s)
+	case Set[uint]:
+		return appendBitsOf(bits, s)
+	case Set[uint8]:
+		return appendBitsOf(bits, s)
+	case Set[uint16]:
+		return appendBitsOf(bits, s)
+	case Set[uint32]:
+		return appendBitsOf(bits, s)
+	case Set[uint64]:
+		return appendBitsOf(bits, s)
+	}
+	for v := range s {
+		rv := reflect.ValueOf(v)
+		if rv.CanInt() {
+			bits = append(bits, uint64(rv.Int()))
+		} else {
+			bits = append(bits, rv.Uint())
+		}
+	}
+	return bits
+}
+
+func appendBitsOf[I integer](bits []uint64, s Set[I]) []uint64 {
+	for v := range s {
+		bits = append(bits, uint64(v))
+	}
+	return bits
+}
+
+// decodeInts fills vals with the little endian integers of size bytes in data. T must have an integer kind.
+// Like appendIntBits, only named integer types use reflect.
+func decodeInts[T any](vals []T, data []byte, size int) {
+	switch ints := any(vals).(type) {
+	case []int:
+		decodeIntsOf(ints, data, size)
+	case []int8:
+		decodeIntsOf(ints, data, size)
+	case []int16:
+		decodeIntsOf(ints, data, size)
+	case []int32:
+		decodeIntsOf(ints, data, size)
+	case []int64:
+		decodeIntsOf(ints, data, size)
+	case []uint:
+		decodeIntsOf(ints, data, size)
+	case []uint8:
+		decodeIntsOf(ints, data, size)
+	case []uint16:
+		decodeIntsOf(ints, data, size)
+	case []uint32:
+		decodeIntsOf(ints, data, size)
+	case []uint64:
+		decodeIntsOf(ints, data, size)
+	default:
+		rv := reflect.ValueOf(vals)
+		// Shifting the bits back sign extends them, since smaller integers were truncated when encoded.
+		shift := 64 - 8*size
+		for i := range vals {
+			u := decodeUint(data[i*size:], size)
+			if e := rv.Index(i); e.CanInt() {
+				e.SetInt(int64(u<<shift) >> shift)
+			} else {
+				e.SetUint(u)
+			}
+		}
+	}
+}
+
+func decodeIntsOf[I integer](vals []I, data []byte, size int) {
+	for i := range vals {
+		// Converting keeps the low bits that were encoded, so negative integers get their sign back.
+		vals[i] = I(decodeUint(data[i*size:], size))
+	}
+}
+
+func decodeUint(data []byte, size int) uint64 {
+	var u uint64
+	for j := range size {
+		u |= uint64(data[j]) << (8 * j)
+	}
+	return u
+}
@@ -0,0 +1,126 @@
+package set
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/hex"
+	"hash/maphash"
+	"math"
+	"testing"
+)
+
+func roundTripBinary[T comparable](t *testing.T, s Set[T]) {
+	t.Helper()
+	b, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary %v", err)
+	}
+	got := From[T](*new(T))
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatalf("UnmarshalBinary %v", err)
+	}
+	if !got.Equal(s) {
+		t.Fatalf("expected %v, got %v", s, got)
+	}
+}
+
+func TestBinary(t *testing.T) {
+	roundTripBinary(t, From[uint64](0, 1, math.MaxUint64))
+	roundTripBinary(t, From[int8](math.MinInt8, -1, 0, 1, math.MaxInt8))
+	roundTripBinary(t, From[int16](math.MinInt16, -300, 300))
+	roundTripBinary(t, From[int32](math.MinInt32, -1, math.MaxInt32))
+	roundTripBinary(t, From(math.MinInt, -1, math.MaxInt))
+	roundTripBinary(t, From[uint](42))
+	roundTripBinary(t, From("a", "b", ""))
+	roundTripBinary(t, From(1.5, -2.25))
+	type point struct{ X, Y int }
+	roundTripBinary(t, From(point{1, 2}, point{3, 4}))
+	roundTripBinary(t, Set[int]{})
+	// Named integer types take the reflect path, but must encode like their underlying type.
+	type temp int16
+	type code uint32
+	roundTripBinary(t, From[temp](math.MinInt16, -40, 0, 451))
+	roundTripBinary(t, From[code](0, 404, math.MaxUint32))
+	if !bytes.Equal(mustBinary(t, From[temp](-40, 451)), mustBinary(t, From[int16](-40, 451))) {
+		t.Fatal("expected a named integer type to encode like its underlying type")
+	}
+
+	var nilSet Set[string]
+	if err := nilSet.UnmarshalBinary(mustBinary(t, From("x"))); err != nil || !nilSet.Equal(From("x")) {
+		t.Fatalf("unexpected UnmarshalBinary into a nil Set %v %v", nilSet, err)
+	}
+
+	ids := From[uint64](1, 2, 3)
+	if b := mustBinary(t, ids); len(b) != 3+3*8 {
+		t.Fatalf("expected fixed size integers, got %d bytes", len(b))
+	}
+	var wrongSize Set[uint32]
+	if err := wrongSize.UnmarshalBinary(mustBinary(t, ids)); err == nil {
+		t.Fatal("expected an error decoding uint64 into uint32")
+	}
+	for _, bad := range [][]byte{nil, {9}, {binaryFixedInt}, {binaryFixedInt, 8, 2, 1}, {binaryGob, 1}} {
+		if err := ids.UnmarshalBinary(bad); err == nil {
+			t.Fatalf("expected an error for %x", bad)
+		}
+	}
+
+	// gob uses MarshalBinary for a Set within another type.
+	type cohort struct {
+		Name string
+		IDs  Set[uint64]
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cohort{Name: "beta", IDs: ids}); err != nil {
+		t.Fatal(err)
+	}
+	var c cohort
+	if err := gob.NewDecoder(&buf).Decode(&c); err != nil || c.Name != "beta" || !c.IDs.Equal(ids) {
+		t.Fatalf("unexpected gob round trip %+v %v", c, err)
+	}
+}
+
+// TestBinaryStable guards the encoding of integers, since it may be decoded by other versions.
+func TestBinaryStable(t *testing.T) {
+	for _, tt := range []struct {
+		b    []byte
+		want string
+	}{
+		{mustBinary(t, From[uint16](258, 1)), "010202" + "0100" + "0201"},
+		{mustBinary(t, From[int8](-1, 1)), "010102" + "01" + "ff"},
+		{mustBinary(t, From[uint32]()), "010400"},
+	} {
+		if got := hex.EncodeToString(tt.b); got != tt.want {
+			t.Fatalf("expected %s, got %s", tt.want, got)
+		}
+	}
+	if !bytes.Equal(mustBinary(t, From("b", "a", "c")), mustBinary(t, From("c", "b", "a"))) {
+		t.Fatal("expected equal sets of strings to encode the same")
+	}
+}
+
+func mustBinary[T comparable](t *testing.T, s Set[T]) []byte {
+	t.Helper()
+	b, err := s.AppendBinary(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestHash(t *testing.T) {
+	seed := maphash.MakeSeed()
+	a := From("x", "y", "z")
+	b := Set[string]{}
+	for _, v := range []string{"z", "x", "y", "x"} {
+		b.Add(v)
+	}
+	if a.Hash(seed) != b.Hash(seed) {
+		t.Fatal("expected equal sets to hash the same")
+	}
+	if a.Hash(seed) == From("x", "y").Hash(seed) || a.Hash(seed) == From("x", "y", "w").Hash(seed) {
+		t.Fatal("expected different sets to hash differently")
+	}
+	if (Set[string]{}).Hash(seed) != 0 {
+		t.Fatal("expected an empty set to hash to 0")
+	}
+}
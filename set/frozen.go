@@ -0,0 +1,59 @@
+package set
+
+import (
+	"cmp"
+	"iter"
+)
+
+// FrozenSet is a read-only view of a Set, for exposing membership from an API without letting callers modify it.
+type FrozenSet[T comparable] struct {
+	s Set[T]
+}
+
+// Freeze returns a read-only view of s. s isn't copied, so changes to s are still visible through the FrozenSet.
+func Freeze[T comparable](s Set[T]) FrozenSet[T] {
+	return FrozenSet[T]{s: s}
+}
+
+// Freeze returns a read-only view of the Set. The Set isn't copied, so don't modify it afterwards unless callers should see the changes.
+func (s Set[T]) Freeze() FrozenSet[T] {
+	return Freeze(s)
+}
+
+// FreezeCopy is Freeze over a Clone of the Set, so the Set can still be modified without affecting the FrozenSet.
+func (s Set[T]) FreezeCopy() FrozenSet[T] {
+	return Freeze(s.Clone())
+}
+
+// Has returns true if v is in the FrozenSet.
+func (f FrozenSet[T]) Has(v T) bool { return f.s.Has(v) }
+
+// HasAll returns true if every value from seq is in the FrozenSet.
+func (f FrozenSet[T]) HasAll(seq iter.Seq[T]) bool { return f.s.HasAll(seq) }
+
+// HasAny returns true if any value from seq is in the FrozenSet.
+func (f FrozenSet[T]) HasAny(seq iter.Seq[T]) bool { return f.s.HasAny(seq) }
+
+// All returns an iterator over the values of the FrozenSet in no particular order.
+func (f FrozenSet[T]) All() iter.Seq[T] { return f.s.All() }
+
+// Len returns the number of values in the FrozenSet.
+func (f FrozenSet[T]) Len() int { return len(f.s) }
+
+// ToSlice returns the values of the FrozenSet in a new slice in no particular order.
+func (f FrozenSet[T]) ToSlice() []T { return f.s.ToSlice() }
+
+// SortedFunc returns an iterator over the values of the FrozenSet ordered by cmp, like SortedFunc.
+func (f FrozenSet[T]) SortedFunc(cmp func(a, b T) int) iter.Seq[T] { return SortedFunc(f.s, cmp) }
+
+// String formats the FrozenSet like Set.String.
+func (f FrozenSet[T]) String() string { return f.s.String() }
+
+// MarshalJSON encodes the FrozenSet as a sorted JSON array like Set.MarshalJSON.
+// There's no UnmarshalJSON, since that would modify the FrozenSet.
+func (f FrozenSet[T]) MarshalJSON() ([]byte, error) { return f.s.MarshalJSON() }
+
+// SortedFrozen returns an iterator over the values of f in ascending order, like Sorted.
+func SortedFrozen[T cmp.Ordered](f FrozenSet[T]) iter.Seq[T] {
+	return Sorted(f.s)
+}
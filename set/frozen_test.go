@@ -0,0 +1,71 @@
+package set
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	s := From(1, 2, 3)
+	f := Freeze(s)
+	if f.Len() != 3 || !f.Has(2) || f.Has(4) {
+		t.Fatalf("unexpected frozen set %v", f)
+	}
+	if !f.HasAll(slices.Values([]int{1, 3})) || f.HasAny(slices.Values([]int{4, 5})) {
+		t.Fatalf("unexpected frozen set %v", f)
+	}
+	if got := FromSeq(f.All()); !maps.Equal(got, s) {
+		t.Fatalf("unexpected values %v", got)
+	}
+
+	// The original Set is still visible through the view.
+	s.Add(4)
+	if !f.Has(4) || f.Len() != 4 {
+		t.Fatal("expected the frozen set to share the original")
+	}
+
+	var zero FrozenSet[int]
+	if zero.Len() != 0 || zero.Has(0) {
+		t.Fatal("expected the zero FrozenSet to be empty")
+	}
+
+	frozenType := reflect.TypeFor[FrozenSet[int]]()
+	for _, mutator := range []string{"Add", "Union", "Difference"} {
+		if _, ok := frozenType.MethodByName(mutator); ok {
+			t.Fatalf("FrozenSet shouldn't have %s", mutator)
+		}
+	}
+}
+
+func TestFreezeCopy(t *testing.T) {
+	s := From(3, 1, 2)
+	shared, copied := s.Freeze(), s.FreezeCopy()
+	s.Add(4)
+	if !shared.Has(4) || copied.Has(4) || copied.Len() != 3 {
+		t.Fatalf("unexpected frozen sets %v %v", shared, copied)
+	}
+
+	if got := slices.Collect(SortedFrozen(copied)); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("unexpected SortedFrozen %v", got)
+	}
+	desc := func(a, b int) int { return b - a }
+	if got := slices.Collect(copied.SortedFunc(desc)); !slices.Equal(got, []int{3, 2, 1}) {
+		t.Fatalf("unexpected SortedFunc %v", got)
+	}
+	if got := copied.ToSlice(); len(got) != 3 || !From(got...).Equal(From(1, 2, 3)) {
+		t.Fatalf("unexpected ToSlice %v", got)
+	}
+	if got := copied.String(); got != "set[1 2 3]" {
+		t.Fatalf("unexpected String %s", got)
+	}
+	b, err := json.Marshal(map[string]FrozenSet[int]{"ids": copied})
+	if err != nil || string(b) != `{"ids":[1,2,3]}` {
+		t.Fatalf("unexpected JSON %s %v", b, err)
+	}
+	if _, ok := reflect.TypeFor[*FrozenSet[int]]().MethodByName("UnmarshalJSON"); ok {
+		t.Fatal("FrozenSet shouldn't have UnmarshalJSON")
+	}
+}
@@ -0,0 +1,47 @@
+package set
+
+// Filter returns a new Set of the values pred returns true for.
+// It ranges over a Clone, so pred can add to or remove from the Set.
+func (s Set[T]) Filter(pred func(T) bool) Set[T] {
+	filtered := make(Set[T])
+	for v := range s.Clone() {
+		if pred(v) {
+			filtered[v] = struct{}{}
+		}
+	}
+	return filtered
+}
+
+// Map returns a new Set of fn applied to every value of s. Values fn maps to the same result are merged.
+// It ranges over a Clone of s, so fn can add to or remove from s.
+func Map[T, U comparable](s Set[T], fn func(T) U) Set[U] {
+	mapped := make(Set[U], len(s))
+	for v := range s.Clone() {
+		mapped[fn(v)] = struct{}{}
+	}
+	return mapped
+}
+
+// Reduce folds every value of s into an accumulator starting at init, in no particular order.
+// It ranges over a Clone of s, so fn can add to or remove from s.
+func Reduce[T comparable, A any](s Set[T], init A, fn func(acc A, v T) A) A {
+	acc := init
+	for v := range s.Clone() {
+		acc = fn(acc, v)
+	}
+	return acc
+}
+
+// Partition splits the Set into a new Set of the values pred returns true for, and a new Set of the rest.
+// It ranges over a Clone, so pred can add to or remove from the Set.
+func (s Set[T]) Partition(pred func(T) bool) (in, out Set[T]) {
+	in, out = make(Set[T], len(s)/2), make(Set[T], len(s)/2)
+	for v := range s.Clone() {
+		if pred(v) {
+			in[v] = struct{}{}
+		} else {
+			out[v] = struct{}{}
+		}
+	}
+	return in, out
+}
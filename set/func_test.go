@@ -0,0 +1,68 @@
+package set
+
+import "testing"
+
+func TestFilterMapReduce(t *testing.T) {
+	s := From(1, 2, 3, 4)
+	even := func(v int) bool { return v%2 == 0 }
+	if got := s.Filter(even); !got.Equal(From(2, 4)) {
+		t.Fatalf("unexpected Filter %v", got)
+	}
+	if got := s.Filter(func(int) bool { return true }); !got.Equal(s) {
+		t.Fatalf("unexpected identity Filter %v", got)
+	}
+	if got := Map(s, func(v int) int { return v }); !got.Equal(s) {
+		t.Fatalf("unexpected identity Map %v", got)
+	}
+	if got := Map(s, even); !got.Equal(From(true, false)) {
+		t.Fatalf("unexpected Map %v", got)
+	}
+	if got := Reduce(s, 0, func(acc, v int) int { return acc + v }); got != 10 {
+		t.Fatalf("unexpected Reduce %d", got)
+	}
+
+	var empty Set[int]
+	if got := empty.Filter(even); got == nil || len(got) != 0 {
+		t.Fatalf("unexpected Filter of empty %v", got)
+	}
+	if got := Map(empty, even); got == nil || len(got) != 0 {
+		t.Fatalf("unexpected Map of empty %v", got)
+	}
+	if got := Reduce(empty, "init", func(acc string, _ int) string { return acc + "!" }); got != "init" {
+		t.Fatalf("unexpected Reduce of empty %q", got)
+	}
+
+	// The callbacks can modify the original set.
+	s.Filter(func(v int) bool {
+		s.Remove(v).Add(v * 10)
+		return true
+	})
+	if !s.Equal(From(10, 20, 30, 40)) {
+		t.Fatalf("unexpected set after Filter %v", s)
+	}
+	calls := Reduce(s, 0, func(acc, v int) int {
+		s.Add(v + 1)
+		return acc + 1
+	})
+	if calls != 4 || len(s) != 8 {
+		t.Fatalf("unexpected Reduce calls %d set %v", calls, s)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	s := From(1, 2, 3, 4, 5)
+	in, out := s.Partition(func(v int) bool { return v%2 == 0 })
+	if !in.Equal(From(2, 4)) || !out.Equal(From(1, 3, 5)) || !s.Equal(From(1, 2, 3, 4, 5)) {
+		t.Fatalf("unexpected Partition %v %v of %v", in, out, s)
+	}
+	in, out = Set[int](nil).Partition(func(int) bool { return true })
+	if in == nil || out == nil || len(in)+len(out) != 0 {
+		t.Fatalf("unexpected Partition of nil %v %v", in, out)
+	}
+
+	// GroupBy shards a Set by key through All.
+	shards := GroupBy(s.All(), func(v int) int { return v % 3 })
+	if len(shards) != 3 || !shards[0].Equal(From(3)) || !shards[1].Equal(From(1, 4)) || !shards[2].Equal(From(2, 5)) {
+		t.Fatalf("unexpected GroupBy %v", shards)
+	}
+}
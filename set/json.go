@@ -0,0 +1,97 @@
+package set
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/danlock/pkg/errors"
+)
+
+// MarshalJSON encodes the Set as a JSON array. Values of a string, integer or float kind are sorted,
+// and other values are sorted by their encoding, so the output is deterministic. A nil Set is encoded as [].
+func (s Set[T]) MarshalJSON() ([]byte, error) {
+	vals := slices.Collect(maps.Keys(s))
+	if vals == nil {
+		vals = []T{}
+	}
+	if sortValues(vals) {
+		return marshalValues(vals)
+	}
+
+	encoded := make([]json.RawMessage, len(vals))
+	for i, v := range vals {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		encoded[i] = b
+	}
+	slices.SortFunc(encoded, func(a, b json.RawMessage) int { return bytes.Compare(a, b) })
+	b, err := json.Marshal(encoded)
+	return b, errors.Wrap(err)
+}
+
+// MarshalSorted encodes s as a JSON array in ascending order, like MarshalJSON but guaranteed sorted by the cmp.Ordered constraint,
+// such as for stable diffs of config files and snapshot tests.
+func MarshalSorted[T cmp.Ordered](s Set[T]) ([]byte, error) {
+	vals := slices.Sorted(maps.Keys(s))
+	if vals == nil {
+		vals = []T{}
+	}
+	return marshalValues(vals)
+}
+
+// marshalValues encodes vals as a JSON array.
+// It goes through []any to keep a Set of bytes from being encoded as a base64 string like a []byte.
+func marshalValues[T any](vals []T) ([]byte, error) {
+	anys := make([]any, len(vals))
+	for i, v := range vals {
+		anys[i] = v
+	}
+	b, err := json.Marshal(anys)
+	return b, errors.Wrap(err)
+}
+
+// UnmarshalJSON decodes a JSON array into the Set, dropping duplicates and replacing its previous contents.
+func (s *Set[T]) UnmarshalJSON(b []byte) error {
+	var vals []T
+	if err := json.Unmarshal(b, &vals); err != nil {
+		return errors.Wrap(err)
+	}
+	if *s == nil {
+		*s = make(Set[T], len(vals))
+	}
+	clear(*s)
+	s.Add(vals...)
+	return nil
+}
+
+// sortValues sorts vals if they have a string, integer or float kind, returning false otherwise.
+func sortValues[T comparable](vals []T) bool {
+	var zero T
+	switch reflect.ValueOf(&zero).Elem().Kind() {
+	case reflect.String:
+		slices.SortFunc(vals, func(a, b T) int {
+			return cmp.Compare(reflect.ValueOf(a).String(), reflect.ValueOf(b).String())
+		})
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		slices.SortFunc(vals, func(a, b T) int {
+			return cmp.Compare(reflect.ValueOf(a).Int(), reflect.ValueOf(b).Int())
+		})
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		slices.SortFunc(vals, func(a, b T) int {
+			return cmp.Compare(reflect.ValueOf(a).Uint(), reflect.ValueOf(b).Uint())
+		})
+	case reflect.Float32, reflect.Float64:
+		slices.SortFunc(vals, func(a, b T) int {
+			return cmp.Compare(reflect.ValueOf(a).Float(), reflect.ValueOf(b).Float())
+		})
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package set
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type testTag string
+
+type testPoint struct{ X, Y int }
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		set  any
+		want string
+	}{
+		{"strings", From("b", "c", "a"), `["a","b","c"]`},
+		{"named strings", From[testTag]("go", "db"), `["db","go"]`},
+		{"ints", From(10, -2, 3), `[-2,3,10]`},
+		{"uints", From[uint8](200, 1), `[1,200]`},
+		{"floats", From(2.5, -1.5), `[-1.5,2.5]`},
+		{"structs", From(testPoint{2, 1}, testPoint{1, 2}), `[{"X":1,"Y":2},{"X":2,"Y":1}]`},
+		{"nil", Set[int](nil), `[]`},
+	}
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.set)
+		if err != nil || string(b) != tt.want {
+			t.Fatalf("%s: unexpected JSON %s err %v", tt.name, b, err)
+		}
+	}
+
+	var doc struct{ Tags Set[string] }
+	if err := json.Unmarshal([]byte(`{"Tags":["go","db","go"]}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Tags.Equal(From("go", "db")) {
+		t.Fatalf("unexpected tags %v", doc.Tags)
+	}
+	b, err := json.Marshal(doc)
+	if err != nil || string(b) != `{"Tags":["db","go"]}` {
+		t.Fatalf("unexpected round trip %s err %v", b, err)
+	}
+
+	var bytes Set[byte]
+	if err := json.Unmarshal([]byte(`[200,1]`), &bytes); err != nil || !bytes.Equal(From[byte](1, 200)) {
+		t.Fatalf("unexpected bytes %v err %v", bytes, err)
+	}
+
+	points := From(testPoint{1, 2})
+	if err := json.Unmarshal([]byte(`[{"X":3,"Y":4}]`), &points); err != nil || !points.Equal(From(testPoint{3, 4})) {
+		t.Fatalf("expected the contents to be replaced, got %v err %v", points, err)
+	}
+
+	for _, input := range []string{`{"a":1}`, `"a"`, `[1]`, `[`} {
+		var s Set[string]
+		if err := json.Unmarshal([]byte(input), &s); err == nil {
+			t.Fatalf("expected an error for %s, got %v", input, s)
+		}
+	}
+}
+
+func TestMarshalSorted(t *testing.T) {
+	s := make(Set[int])
+	for _, v := range []int{42, -7, 3, 1000, 0, 3} {
+		s.Add(v)
+	}
+	if b, err := MarshalSorted(s); err != nil || string(b) != `[-7,0,3,42,1000]` {
+		t.Fatalf("unexpected JSON %s err %v", b, err)
+	}
+	if b, err := MarshalSorted(From[byte](2, 1)); err != nil || string(b) != `[1,2]` {
+		t.Fatalf("unexpected JSON %s err %v", b, err)
+	}
+	if b, err := MarshalSorted(Set[string](nil)); err != nil || string(b) != `[]` {
+		t.Fatalf("unexpected JSON %s err %v", b, err)
+	}
+}
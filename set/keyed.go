@@ -0,0 +1,78 @@
+package set
+
+import (
+	"iter"
+	"maps"
+)
+
+// KeyedSet is a set of values identified by a key, for values that aren't comparable or where identity is a field,
+// such as users deduplicated by ID while keeping the full struct.
+// By default adding a value with a key already in the KeyedSet replaces the previous value.
+type KeyedSet[T any, K comparable] struct {
+	// KeepFirst keeps the value already in the KeyedSet when adding a value with the same key, instead of replacing it.
+	KeepFirst bool
+
+	key  func(T) K
+	vals map[K]T
+}
+
+// NewKeyed creates an empty KeyedSet identifying values by key.
+func NewKeyed[T any, K comparable](key func(T) K) *KeyedSet[T, K] {
+	return &KeyedSet[T, K]{key: key, vals: make(map[K]T)}
+}
+
+// Add adds vals to the KeyedSet and returns it. A value whose key is already in the KeyedSet replaces it, unless KeepFirst is set.
+func (s *KeyedSet[T, K]) Add(vals ...T) *KeyedSet[T, K] {
+	for _, v := range vals {
+		k := s.key(v)
+		if _, ok := s.vals[k]; ok && s.KeepFirst {
+			continue
+		}
+		s.vals[k] = v
+	}
+	return s
+}
+
+// Has returns true if a value with key k is in the KeyedSet.
+func (s *KeyedSet[T, K]) Has(k K) bool {
+	_, ok := s.vals[k]
+	return ok
+}
+
+// Get returns the value with key k, or false if there isn't one.
+func (s *KeyedSet[T, K]) Get(k K) (T, bool) {
+	v, ok := s.vals[k]
+	return v, ok
+}
+
+// Remove removes the values with keys and returns the KeyedSet.
+func (s *KeyedSet[T, K]) Remove(keys ...K) *KeyedSet[T, K] {
+	for _, k := range keys {
+		delete(s.vals, k)
+	}
+	return s
+}
+
+// Len returns the number of values in the KeyedSet.
+func (s *KeyedSet[T, K]) Len() int {
+	return len(s.vals)
+}
+
+// All returns an iterator over the values of the KeyedSet in no particular order.
+func (s *KeyedSet[T, K]) All() iter.Seq[T] {
+	return maps.Values(s.vals)
+}
+
+// Keys returns a new Set of the keys of the KeyedSet.
+func (s *KeyedSet[T, K]) Keys() Set[K] {
+	return FromMapKeys(s.vals)
+}
+
+// Union adds every value of other to the KeyedSet like Add and returns it.
+// other's values are keyed by this KeyedSet's key func, so both should use the same one.
+func (s *KeyedSet[T, K]) Union(other *KeyedSet[T, K]) *KeyedSet[T, K] {
+	for v := range other.All() {
+		s.Add(v)
+	}
+	return s
+}
@@ -0,0 +1,59 @@
+package set
+
+import (
+	"slices"
+	"testing"
+)
+
+type testUser struct {
+	ID    int
+	Name  string
+	Roles []string
+}
+
+func TestKeyedSet(t *testing.T) {
+	byID := func(u testUser) int { return u.ID }
+	users := NewKeyed(byID).Add(
+		testUser{ID: 1, Name: "ann", Roles: []string{"admin"}},
+		testUser{ID: 2, Name: "bob"},
+		testUser{ID: 1, Name: "ann v2"},
+		testUser{Name: "zero"},
+	)
+	if users.Len() != 3 || !users.Has(0) || !users.Has(2) || users.Has(3) {
+		t.Fatalf("unexpected keys %v", users.Keys())
+	}
+	if u, ok := users.Get(1); !ok || u.Name != "ann v2" {
+		t.Fatalf("expected the last value to win, got %+v", u)
+	}
+	if u, ok := users.Get(0); !ok || u.Name != "zero" {
+		t.Fatalf("expected the zero value key to work, got %+v", u)
+	}
+	if _, ok := users.Get(3); ok {
+		t.Fatal("unexpected value for a missing key")
+	}
+
+	first := NewKeyed(byID)
+	first.KeepFirst = true
+	first.Add(testUser{ID: 1, Name: "ann"}, testUser{ID: 1, Name: "ann v2"})
+	if u, _ := first.Get(1); u.Name != "ann" {
+		t.Fatalf("expected the first value to win, got %+v", u)
+	}
+
+	// Union follows the receiver's collision rule.
+	first.Union(users)
+	if u, _ := first.Get(1); u.Name != "ann" || first.Len() != 3 {
+		t.Fatalf("unexpected Union %+v", slices.Collect(first.All()))
+	}
+	users.Union(NewKeyed(byID).Add(testUser{ID: 2, Name: "bob v2"}))
+	if u, _ := users.Get(2); u.Name != "bob v2" {
+		t.Fatalf("unexpected Union %+v", u)
+	}
+
+	users.Remove(0, 1, 5)
+	if names := slices.Collect(users.All()); len(names) != 1 || names[0].Name != "bob v2" {
+		t.Fatalf("unexpected values %+v", names)
+	}
+	if !users.Keys().Equal(From(2)) {
+		t.Fatalf("unexpected keys %v", users.Keys())
+	}
+}
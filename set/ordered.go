@@ -0,0 +1,86 @@
+package set
+
+import (
+	"iter"
+	"slices"
+)
+
+// OrderedSet is a set that remembers the order values were first added in, for deduplicated lists where order matters.
+// Add and Has are O(1) like a Set. Remove is O(n) since the values after the removed one are shifted down to keep the order compact.
+// Adding a value already in the OrderedSet keeps its original position. The zero value is ready to use.
+type OrderedSet[T comparable] struct {
+	index map[T]int
+	vals  []T
+}
+
+// OrderedFrom creates an OrderedSet containing vals in order, dropping duplicates.
+func OrderedFrom[T comparable](vals ...T) *OrderedSet[T] {
+	return new(OrderedSet[T]).Add(vals...)
+}
+
+// OrderedFromSeq creates an OrderedSet containing every value from seq in order, dropping duplicates.
+// Pass Sorted(s) to convert a Set to an OrderedSet with a deterministic order.
+func OrderedFromSeq[T comparable](seq iter.Seq[T]) *OrderedSet[T] {
+	o := new(OrderedSet[T])
+	for v := range seq {
+		o.Add(v)
+	}
+	return o
+}
+
+// Add appends each of vals not already in the OrderedSet and returns it.
+func (o *OrderedSet[T]) Add(vals ...T) *OrderedSet[T] {
+	if o.index == nil {
+		o.index = make(map[T]int, len(vals))
+	}
+	for _, v := range vals {
+		if _, ok := o.index[v]; !ok {
+			o.index[v] = len(o.vals)
+			o.vals = append(o.vals, v)
+		}
+	}
+	return o
+}
+
+// Has returns true if v is in the OrderedSet.
+func (o *OrderedSet[T]) Has(v T) bool {
+	_, ok := o.index[v]
+	return ok
+}
+
+// Remove removes vals from the OrderedSet and returns it, keeping the order of the remaining values.
+func (o *OrderedSet[T]) Remove(vals ...T) *OrderedSet[T] {
+	for _, v := range vals {
+		i, ok := o.index[v]
+		if !ok {
+			continue
+		}
+		delete(o.index, v)
+		o.vals = slices.Delete(o.vals, i, i+1)
+		for j := i; j < len(o.vals); j++ {
+			o.index[o.vals[j]] = j
+		}
+	}
+	return o
+}
+
+// Len returns the number of values in the OrderedSet.
+func (o *OrderedSet[T]) Len() int {
+	return len(o.vals)
+}
+
+// All returns an iterator over the values of the OrderedSet in the order they were added.
+// The OrderedSet must not be modified during iteration.
+func (o *OrderedSet[T]) All() iter.Seq[T] {
+	return slices.Values(o.vals)
+}
+
+// Values returns a copy of the values of the OrderedSet in the order they were added.
+func (o *OrderedSet[T]) Values() []T {
+	return slices.Clone(o.vals)
+}
+
+// Set returns a new Set of the values in the OrderedSet.
+func (o *OrderedSet[T]) Set() Set[T] {
+	return From(o.vals...)
+}
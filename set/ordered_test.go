@@ -0,0 +1,42 @@
+package set
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOrderedSet(t *testing.T) {
+	var o OrderedSet[string]
+	if o.Has("a") || o.Len() != 0 || len(o.Values()) != 0 {
+		t.Fatal("expected the zero value to be empty")
+	}
+
+	o.Add("c", "a", "c", "b").Remove("a").Add("d", "a", "b").Remove("c", "missing").Add("c")
+	if got := o.Values(); !slices.Equal(got, []string{"b", "d", "a", "c"}) {
+		t.Fatalf("unexpected order %v", got)
+	}
+	if !o.Has("a") || o.Has("missing") || o.Len() != 4 {
+		t.Fatalf("unexpected membership %v", o.Values())
+	}
+
+	// Every index is kept in sync through removals from the middle, start and end.
+	o.Remove("d").Remove("b").Remove("c")
+	o.Add("e", "f")
+	if got := slices.Collect(o.All()); !slices.Equal(got, []string{"a", "e", "f"}) {
+		t.Fatalf("unexpected order %v", got)
+	}
+	o.Remove("e")
+	if got := o.Values(); !slices.Equal(got, []string{"a", "f"}) {
+		t.Fatalf("unexpected order %v", got)
+	}
+
+	if !o.Set().Equal(From("a", "f")) {
+		t.Fatalf("unexpected Set %v", o.Set())
+	}
+	if got := OrderedFromSeq(Sorted(From(3, 1, 2))).Values(); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("unexpected order %v", got)
+	}
+	if got := OrderedFrom(2, 1, 2).Values(); !slices.Equal(got, []int{2, 1}) {
+		t.Fatalf("unexpected order %v", got)
+	}
+}
@@ -0,0 +1,36 @@
+package set
+
+import (
+	"iter"
+	"math/rand/v2"
+)
+
+// Sample returns a new Set of n values chosen uniformly at random from s, or a copy of s if n >= len(s).
+func Sample[T comparable](s Set[T], n int) Set[T] {
+	sample := make(Set[T], max(0, min(n, len(s))))
+	for v := range SampleSeq(s, n) {
+		sample[v] = struct{}{}
+	}
+	return sample
+}
+
+// SampleSeq is Sample without allocating the result, yielding n values chosen uniformly at random from s.
+// Each value is selected while iterating s with probability (values still needed) / (values left),
+// so a single pass over s is enough.
+func SampleSeq[T comparable](s Set[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		left, need := len(s), n
+		for v := range s {
+			if need <= 0 {
+				return
+			}
+			if rand.IntN(left) < need {
+				need--
+				if !yield(v) {
+					return
+				}
+			}
+			left--
+		}
+	}
+}
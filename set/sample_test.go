@@ -0,0 +1,56 @@
+package set
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	s := From(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	for n := -1; n <= 12; n++ {
+		sample := Sample(s, n)
+		if want := max(0, min(n, len(s))); len(sample) != want {
+			t.Fatalf("Sample(s, %d) has %d values", n, len(sample))
+		}
+		if !s.HasAll(sample.All()) {
+			t.Fatalf("Sample(s, %d) == %v isn't a subset of %v", n, sample, s)
+		}
+	}
+	if sample := Sample(s, len(s)); !maps.Equal(sample, s) {
+		t.Fatalf("expected the whole set, got %v", sample)
+	}
+	if len(Sample(Set[int](nil), 3)) != 0 {
+		t.Fatal("expected an empty sample")
+	}
+
+	// Every value should be picked roughly as often as the others.
+	counts := make(map[int]int)
+	const rounds = 10000
+	for range rounds {
+		for v := range SampleSeq(s, 3) {
+			counts[v]++
+		}
+	}
+	for v := range s {
+		if expected := rounds * 3 / len(s); counts[v] < expected*8/10 || counts[v] > expected*12/10 {
+			t.Fatalf("value %d sampled %d times, expected around %d", v, counts[v], expected)
+		}
+	}
+
+	// Stopping early is respected.
+	for range SampleSeq(s, 5) {
+		break
+	}
+}
+
+func TestSampleSeqReuse(t *testing.T) {
+	s := From(1, 2, 3, 4, 5)
+	for _, n := range []int{3, 5, 10} {
+		seq := SampleSeq(s, n)
+		for pass := range 2 {
+			if got := FromSeq(seq); len(got) != min(n, len(s)) {
+				t.Fatalf("pass %d of SampleSeq(%d) yielded %v", pass, n, got)
+			}
+		}
+	}
+}
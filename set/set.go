@@ -0,0 +1,352 @@
+// Package set provides a generic Set backed by a map, with methods that can be chained.
+// Set is not thread safe, use SyncSet for concurrent access.
+package set
+
+import (
+	"iter"
+	"maps"
+)
+
+// Set is a collection of unique values.
+type Set[T comparable] map[T]struct{}
+
+// From creates a Set containing vals.
+func From[T comparable](vals ...T) Set[T] {
+	return make(Set[T], len(vals)).Add(vals...)
+}
+
+// WithCapacity creates an empty Set with room for about n values, avoiding repeated growth when adding them one at a time.
+func WithCapacity[T comparable](n int) Set[T] {
+	return make(Set[T], n)
+}
+
+// FromSeq creates a Set containing every value from seq.
+func FromSeq[T comparable](seq iter.Seq[T]) Set[T] {
+	return make(Set[T]).Union(seq)
+}
+
+// FromMapKeys creates a Set of the keys of m, which can be any map type such as http.Header.
+func FromMapKeys[M ~map[K]V, K comparable, V any](m M) Set[K] {
+	s := make(Set[K], len(m))
+	for k := range m {
+		s[k] = struct{}{}
+	}
+	return s
+}
+
+// FromMapValues creates a Set of the unique values of m, which can be any map type.
+func FromMapValues[M ~map[K]V, K comparable, V comparable](m M) Set[V] {
+	return FromSeq(maps.Values(m))
+}
+
+// GroupBy partitions the values of vals into a Set for each key returned by key, dropping duplicates.
+func GroupBy[T comparable, K comparable](vals iter.Seq[T], key func(T) K) map[K]Set[T] {
+	groups := make(map[K]Set[T])
+	for v := range vals {
+		k := key(v)
+		if groups[k] == nil {
+			groups[k] = make(Set[T])
+		}
+		groups[k][v] = struct{}{}
+	}
+	return groups
+}
+
+// Add adds vals to the Set and returns it.
+func (s Set[T]) Add(vals ...T) Set[T] {
+	for _, v := range vals {
+		s[v] = struct{}{}
+	}
+	return s
+}
+
+// AddReported adds v to the Set, returning true if it wasn't already in the Set.
+func (s Set[T]) AddReported(v T) bool {
+	if s.Has(v) {
+		return false
+	}
+	s[v] = struct{}{}
+	return true
+}
+
+// Remove removes vals from the Set and returns it.
+func (s Set[T]) Remove(vals ...T) Set[T] {
+	for _, v := range vals {
+		delete(s, v)
+	}
+	return s
+}
+
+// Delete removes v from the Set, returning true if it was in the Set.
+func (s Set[T]) Delete(v T) bool {
+	if !s.Has(v) {
+		return false
+	}
+	delete(s, v)
+	return true
+}
+
+// Pop removes and returns an arbitrary value from the Set, or returns false if the Set is empty.
+func (s Set[T]) Pop() (v T, ok bool) {
+	for v = range s {
+		delete(s, v)
+		return v, true
+	}
+	return v, false
+}
+
+// Has returns true if v is in the Set.
+func (s Set[T]) Has(v T) bool {
+	_, ok := s[v]
+	return ok
+}
+
+// HasAll returns true if every value from seq is in the Set.
+func (s Set[T]) HasAll(seq iter.Seq[T]) bool {
+	for v := range seq {
+		if !s.Has(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasAny returns true if any value from seq is in the Set.
+func (s Set[T]) HasAny(seq iter.Seq[T]) bool {
+	for v := range seq {
+		if s.Has(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDisjoint returns true if no value from seq is in the Set, stopping at the first value found in both.
+func (s Set[T]) IsDisjoint(seq iter.Seq[T]) bool {
+	return !s.HasAny(seq)
+}
+
+// All returns an iterator over the values of the Set in no particular order.
+func (s Set[T]) All() iter.Seq[T] {
+	return maps.Keys(s)
+}
+
+// Equal returns true if the Set and b have the same values. A nil Set equals an empty one.
+func (s Set[T]) Equal(b Set[T]) bool {
+	if len(s) != len(b) {
+		return false
+	}
+	for v := range s {
+		if !b.Has(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns a copy of the Set. Cloning a nil Set returns an empty Set, so the copy can always be added to.
+func (s Set[T]) Clone() Set[T] {
+	if s == nil {
+		return make(Set[T])
+	}
+	return maps.Clone(s)
+}
+
+// Copy is Clone.
+func (s Set[T]) Copy() Set[T] {
+	return s.Clone()
+}
+
+// Size returns the number of values in the Set, the same as len(s).
+func (s Set[T]) Size() int {
+	return len(s)
+}
+
+// IsEmpty returns true if the Set has no values.
+func (s Set[T]) IsEmpty() bool {
+	return len(s) == 0
+}
+
+// ToSlice returns the values of the Set in a new slice, in no particular order.
+func (s Set[T]) ToSlice() []T {
+	return s.AppendTo(make([]T, 0, len(s)))
+}
+
+// AppendTo appends the values of the Set to dst in no particular order and returns the extended slice, like slices.AppendSeq.
+func (s Set[T]) AppendTo(dst []T) []T {
+	for v := range s {
+		dst = append(dst, v)
+	}
+	return dst
+}
+
+// Union adds every value from seq to the Set and returns it.
+func (s Set[T]) Union(seq iter.Seq[T]) Set[T] {
+	for v := range seq {
+		s[v] = struct{}{}
+	}
+	return s
+}
+
+// Difference removes every value from seq from the Set and returns it.
+func (s Set[T]) Difference(seq iter.Seq[T]) Set[T] {
+	for v := range seq {
+		delete(s, v)
+	}
+	return s
+}
+
+// Intersection returns a new Set of the values from seq that are also in the Set.
+func (s Set[T]) Intersection(seq iter.Seq[T]) Set[T] {
+	inter := make(Set[T])
+	for v := range seq {
+		if s.Has(v) {
+			inter[v] = struct{}{}
+		}
+	}
+	return inter
+}
+
+// Intersects returns a new Set of the values from seq that are also in the Set.
+//
+// Deprecated: Use Intersection, or IntersectsWith to check if there are any values in common.
+func (s Set[T]) Intersects(seq iter.Seq[T]) Set[T] {
+	return s.Intersection(seq)
+}
+
+// IntersectsWith returns true if any value from seq is in the Set, stopping at the first value found in both.
+// It's cheaper than checking the length of Intersection, which builds a new Set.
+func (s Set[T]) IntersectsWith(seq iter.Seq[T]) bool {
+	return s.HasAny(seq)
+}
+
+// UnionNew returns a new Set of the values in the Set or seq, leaving the Set unchanged.
+func (s Set[T]) UnionNew(seq iter.Seq[T]) Set[T] {
+	return s.Clone().Union(seq)
+}
+
+// DifferenceNew returns a new Set of the values in the Set that aren't in seq, leaving the Set unchanged.
+func (s Set[T]) DifferenceNew(seq iter.Seq[T]) Set[T] {
+	return s.Clone().Difference(seq)
+}
+
+// SymmetricDifference returns a new Set of the values in exactly one of the Set and seq, leaving the Set unchanged.
+// seq is collected into a Set first since it's needed twice, costing an allocation proportional to its length.
+func (s Set[T]) SymmetricDifference(seq iter.Seq[T]) Set[T] {
+	return SymmetricDifferenceOf(s, FromSeq(seq))
+}
+
+// UnionOf returns a new Set of the values in a or b.
+func UnionOf[T comparable](a, b Set[T]) Set[T] {
+	return a.UnionNew(b.All())
+}
+
+// DifferenceOf returns a new Set of the values in a that aren't in b.
+func DifferenceOf[T comparable](a, b Set[T]) Set[T] {
+	diff := make(Set[T])
+	for v := range a {
+		if !b.Has(v) {
+			diff[v] = struct{}{}
+		}
+	}
+	return diff
+}
+
+// IntersectionOf returns a new Set of the values in both a and b.
+func IntersectionOf[T comparable](a, b Set[T]) Set[T] {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return b.Intersection(a.All())
+}
+
+// Merge returns a new Set of the values in any of sets.
+func Merge[T comparable](sets ...Set[T]) Set[T] {
+	size := 0
+	for _, s := range sets {
+		size = max(size, len(s))
+	}
+	return MergeInto(make(Set[T], size), sets...)
+}
+
+// MergeInto adds the values of every one of sets to target and returns it, like maps.Copy for sets.
+func MergeInto[T comparable](target Set[T], sets ...Set[T]) Set[T] {
+	for _, s := range sets {
+		target.Union(s.All())
+	}
+	return target
+}
+
+// UnionAll returns a new Set of the values in any of sets, without modifying them. It's Merge by the name that pairs with IntersectionAll.
+// With no sets it returns an empty Set.
+func UnionAll[T comparable](sets ...Set[T]) Set[T] {
+	return Merge(sets...)
+}
+
+// IntersectionAll returns a new Set of the values in every one of sets, without modifying them.
+// It starts from the smallest Set and stops early once no values are left.
+// With a single Set it returns a Clone, and with no sets it returns an empty Set.
+func IntersectionAll[T comparable](sets ...Set[T]) Set[T] {
+	if len(sets) == 0 {
+		return make(Set[T])
+	}
+	smallest := 0
+	for i, s := range sets {
+		if len(s) < len(sets[smallest]) {
+			smallest = i
+		}
+	}
+	inter := sets[smallest].Clone()
+	for i, s := range sets {
+		if len(inter) == 0 {
+			break
+		}
+		if i == smallest {
+			continue
+		}
+		for v := range inter {
+			if !s.Has(v) {
+				delete(inter, v)
+			}
+		}
+	}
+	return inter
+}
+
+// SymmetricDifferenceOf returns a new Set of the values in exactly one of a and b.
+func SymmetricDifferenceOf[T comparable](a, b Set[T]) Set[T] {
+	diff := DifferenceOf(a, b)
+	for v := range b {
+		if !a.Has(v) {
+			diff[v] = struct{}{}
+		}
+	}
+	return diff
+}
+
+// Disjoint returns true if a and b have no values in common.
+// It iterates the smaller Set, stopping at the first value found in both.
+func Disjoint[T comparable](a, b Set[T]) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	for v := range a {
+		if b.Has(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// DisjointAll returns true if no value is in more than one of the sets.
+// Each pair of sets is compared with Disjoint, so it doesn't allocate.
+func DisjointAll[T comparable](sets ...Set[T]) bool {
+	for i := range sets {
+		for j := i + 1; j < len(sets); j++ {
+			if !Disjoint(sets[i], sets[j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
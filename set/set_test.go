@@ -0,0 +1,341 @@
+package set
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	s := From(1, 2, 3, 3)
+	if len(s) != 3 || !s.Has(1) || s.Has(4) {
+		t.Fatalf("unexpected set %v", s)
+	}
+	if !s.HasAll(slices.Values([]int{1, 2})) || s.HasAll(slices.Values([]int{1, 4})) {
+		t.Fatalf("unexpected HasAll for %v", s)
+	}
+	if !s.HasAny(slices.Values([]int{4, 3})) || s.HasAny(slices.Values([]int{4, 5})) {
+		t.Fatalf("unexpected HasAny for %v", s)
+	}
+
+	s.Union(slices.Values([]int{4, 5})).Difference(slices.Values([]int{1, 2}))
+	if !maps.Equal(s, From(3, 4, 5)) {
+		t.Fatalf("unexpected set after Union and Difference %v", s)
+	}
+	if inter := s.Intersection(slices.Values([]int{5, 6})); !maps.Equal(inter, From(5)) || len(s) != 3 {
+		t.Fatalf("unexpected intersection %v of %v", inter, s)
+	}
+	if !s.IntersectsWith(slices.Values([]int{6, 5})) || s.IntersectsWith(slices.Values([]int{1, 6})) {
+		t.Fatalf("unexpected IntersectsWith of %v", s)
+	}
+	if got := FromSeq(s.All()); !maps.Equal(got, s) {
+		t.Fatalf("FromSeq(s.All()) == %v, expected %v", got, s)
+	}
+}
+
+func TestFromMap(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 1, "d": 1}
+	if keys := FromMapKeys(m); !maps.Equal(keys, From("a", "b", "c", "d")) {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+	if vals := FromMapValues(m); !maps.Equal(vals, From(1, 2)) {
+		t.Fatalf("expected duplicate values to collapse, got %v", vals)
+	}
+	if keys := FromMapKeys(map[string]int(nil)); len(keys) != 0 || keys == nil {
+		t.Fatalf("expected an empty set, got %v", keys)
+	}
+	if vals := FromMapValues(map[string]int{}); len(vals) != 0 {
+		t.Fatalf("expected an empty set, got %v", vals)
+	}
+}
+
+func TestDisjoint(t *testing.T) {
+	tests := []struct {
+		a, b Set[int]
+		want bool
+	}{
+		{From(1, 2), From(3, 4, 5), true},
+		{From(1, 2), From(2, 3, 4), false},
+		{From(1, 2, 3, 4), From(4), false},
+		{From[int](), From(1), true},
+		{nil, nil, true},
+	}
+	for _, tt := range tests {
+		if got := Disjoint(tt.a, tt.b); got != tt.want {
+			t.Fatalf("Disjoint(%v, %v) == %t", tt.a, tt.b, got)
+		}
+		if got := Disjoint(tt.b, tt.a); got != tt.want {
+			t.Fatalf("Disjoint(%v, %v) == %t", tt.b, tt.a, got)
+		}
+		if got := tt.a.IsDisjoint(tt.b.All()); got != tt.want {
+			t.Fatalf("%v.IsDisjoint(%v) == %t", tt.a, tt.b, got)
+		}
+	}
+
+	if !DisjointAll(From(1), From(2), From(3, 4)) || !DisjointAll[int]() || !DisjointAll(From(1)) {
+		t.Fatal("expected DisjointAll to be true")
+	}
+	if DisjointAll(From(1), From(2), From(3, 1)) {
+		t.Fatal("expected DisjointAll to be false")
+	}
+}
+
+func TestNonMutating(t *testing.T) {
+	a, b := From(1, 2, 3), From(3, 4)
+	check := func(op string, got Set[int], want ...int) {
+		t.Helper()
+		if !maps.Equal(got, From(want...)) {
+			t.Fatalf("%s == %v, expected %v", op, got, want)
+		}
+		if !maps.Equal(a, From(1, 2, 3)) || !maps.Equal(b, From(3, 4)) {
+			t.Fatalf("%s modified its inputs %v %v", op, a, b)
+		}
+	}
+
+	check("UnionNew", a.UnionNew(b.All()), 1, 2, 3, 4)
+	check("DifferenceNew", a.DifferenceNew(b.All()), 1, 2)
+	check("UnionOf", UnionOf(a, b), 1, 2, 3, 4)
+	check("DifferenceOf", DifferenceOf(a, b), 1, 2)
+	check("DifferenceOf", DifferenceOf(b, a), 4)
+	check("IntersectionOf", IntersectionOf(a, b), 3)
+	check("IntersectionOf", IntersectionOf(b, a), 3)
+
+	// The results are new sets, so changing them leaves the inputs alone too.
+	check("UnionOf then Add", UnionOf(a, b).Add(5), 1, 2, 3, 4, 5)
+	check("UnionNew of nil", Set[int](nil).UnionNew(a.All()).Add(6), 1, 2, 3, 6)
+}
+
+func TestGroupBy(t *testing.T) {
+	words := slices.Values([]string{"go", "set", "map", "go", "iter", "slog", "set", "a"})
+	groups := GroupBy(words, func(w string) int { return len(w) })
+	want := map[int]Set[string]{
+		1: From("a"),
+		2: From("go"),
+		3: From("set", "map"),
+		4: From("iter", "slog"),
+	}
+	if !maps.EqualFunc(groups, want, maps.Equal) {
+		t.Fatalf("unexpected groups %v", groups)
+	}
+	if groups := GroupBy(slices.Values([]int(nil)), func(int) bool { return true }); len(groups) != 0 {
+		t.Fatalf("unexpected groups %v", groups)
+	}
+}
+
+func TestSymmetricDifference(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Set[int]
+		want Set[int]
+	}{
+		{"overlapping", From(1, 2, 3), From(3, 4), From(1, 2, 4)},
+		{"disjoint", From(1, 2), From(3), From(1, 2, 3)},
+		{"identical", From(1, 2), From(1, 2), From[int]()},
+		{"empty", From[int](), From(1), From(1)},
+		{"nil", nil, nil, From[int]()},
+	}
+	for _, tt := range tests {
+		a, b := maps.Clone(tt.a), maps.Clone(tt.b)
+		if got := tt.a.SymmetricDifference(tt.b.All()); !maps.Equal(got, tt.want) {
+			t.Fatalf("%s: SymmetricDifference == %v, expected %v", tt.name, got, tt.want)
+		}
+		if got := SymmetricDifferenceOf(tt.b, tt.a); !maps.Equal(got, tt.want) {
+			t.Fatalf("%s: SymmetricDifferenceOf == %v, expected %v", tt.name, got, tt.want)
+		}
+		if !maps.Equal(a, tt.a) || !maps.Equal(b, tt.b) {
+			t.Fatalf("%s: inputs modified %v %v", tt.name, tt.a, tt.b)
+		}
+	}
+}
+
+func TestEqualClone(t *testing.T) {
+	var nilSet Set[int]
+	a := From(1, 2, 3)
+	tests := []struct {
+		a, b Set[int]
+		want bool
+	}{
+		{a, a, true},
+		{a, From(3, 2, 1), true},
+		{a, From(1, 2), false},
+		{a, From(1, 2, 4), false},
+		{nilSet, From[int](), true},
+		{nilSet, nilSet, true},
+		{nilSet, a, false},
+	}
+	for i, tt := range tests {
+		if got := tt.a.Equal(tt.b); got != tt.want {
+			t.Fatalf("%d: %v.Equal(%v) == %t", i, tt.a, tt.b, got)
+		}
+		if got := tt.b.Equal(tt.a); got != tt.want {
+			t.Fatalf("%d: %v.Equal(%v) == %t", i, tt.b, tt.a, got)
+		}
+	}
+
+	c := a.Clone()
+	if !c.Equal(a) {
+		t.Fatalf("unexpected clone %v", c)
+	}
+	c.Add(4)
+	if a.Has(4) {
+		t.Fatal("expected the clone to be a copy")
+	}
+	if c := a.Copy().Add(5); !c.Equal(From(1, 2, 3, 5)) || a.Has(5) {
+		t.Fatalf("unexpected copy %v", c)
+	}
+	if a.Size() != 3 || a.IsEmpty() || nilSet.Size() != 0 || !nilSet.IsEmpty() || !From[int]().IsEmpty() {
+		t.Fatalf("unexpected Size %d IsEmpty %t", a.Size(), a.IsEmpty())
+	}
+	if c := nilSet.Clone().Add(1); !c.Equal(From(1)) {
+		t.Fatalf("unexpected clone of nil %v", c)
+	}
+	if c := From[int]().Clone(); c == nil || len(c) != 0 {
+		t.Fatalf("unexpected clone of empty %v", c)
+	}
+}
+
+func TestRemovePop(t *testing.T) {
+	s := From(1, 2, 3, 4)
+	if !s.Remove(1, 5).Remove().Equal(From(2, 3, 4)) {
+		t.Fatalf("unexpected set %v", s)
+	}
+	if !s.Delete(2) || s.Delete(2) || s.Has(2) {
+		t.Fatalf("unexpected Delete result %v", s)
+	}
+	if !s.AddReported(5) || s.AddReported(5) || !s.Has(5) {
+		t.Fatalf("unexpected AddReported result %v", s)
+	}
+
+	popped := From[int]()
+	for v, ok := s.Pop(); ok; v, ok = s.Pop() {
+		if !popped.AddReported(v) {
+			t.Fatalf("popped %d twice", v)
+		}
+	}
+	if len(s) != 0 || !popped.Equal(From(3, 4, 5)) {
+		t.Fatalf("unexpected popped %v left %v", popped, s)
+	}
+	if v, ok := s.Pop(); ok || v != 0 {
+		t.Fatalf("unexpected Pop of empty set %d %t", v, ok)
+	}
+	if v, ok := Set[string](nil).Pop(); ok || v != "" {
+		t.Fatalf("unexpected Pop of nil set %q %t", v, ok)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, b, c := From(1, 2), From(2, 3), From(4)
+	if got := Merge(a, b, nil, c); !got.Equal(From(1, 2, 3, 4)) {
+		t.Fatalf("unexpected Merge %v", got)
+	}
+	if !a.Equal(From(1, 2)) || !b.Equal(From(2, 3)) {
+		t.Fatalf("Merge modified its inputs %v %v", a, b)
+	}
+	if got := Merge[int](); got == nil || len(got) != 0 {
+		t.Fatalf("unexpected Merge %v", got)
+	}
+
+	if got := MergeInto(a, b, c).Add(5); !a.Equal(From(1, 2, 3, 4, 5)) || !got.Equal(a) {
+		t.Fatalf("unexpected MergeInto %v", a)
+	}
+}
+
+func TestUnionAllIntersectionAll(t *testing.T) {
+	a, b, c := From(1, 2, 3, 4), From(2, 3, 4, 5), From(3, 4, 6)
+	if got := UnionAll(a, b, c); !got.Equal(From(1, 2, 3, 4, 5, 6)) {
+		t.Fatalf("unexpected UnionAll %v", got)
+	}
+	if got := IntersectionAll(a, b, c); !got.Equal(From(3, 4)) {
+		t.Fatalf("unexpected IntersectionAll %v", got)
+	}
+	if !a.Equal(From(1, 2, 3, 4)) || !b.Equal(From(2, 3, 4, 5)) || !c.Equal(From(3, 4, 6)) {
+		t.Fatalf("modified inputs %v %v %v", a, b, c)
+	}
+	if got := IntersectionAll(a, b, Set[int]{}, c); got == nil || len(got) != 0 {
+		t.Fatalf("unexpected IntersectionAll with an empty set %v", got)
+	}
+
+	if got := UnionAll[int](); got == nil || len(got) != 0 {
+		t.Fatalf("unexpected UnionAll %v", got)
+	}
+	if got := IntersectionAll[int](); got == nil || len(got) != 0 {
+		t.Fatalf("unexpected IntersectionAll %v", got)
+	}
+	if got := IntersectionAll(a); !got.Equal(a) || got.Add(9).Equal(a) {
+		t.Fatalf("unexpected IntersectionAll of a single set %v", got)
+	}
+	if got := UnionAll(a); !got.Equal(a) || got.Add(9).Equal(a) {
+		t.Fatalf("unexpected UnionAll of a single set %v", got)
+	}
+}
+
+func TestIntersectionAllTags(t *testing.T) {
+	docs := []Set[string]{
+		From("go", "sets", "generics"),
+		From("go", "generics", "iterators"),
+		From("generics", "go"),
+		From("go", "generics", "maps", "sets"),
+	}
+	if got := IntersectionAll(docs...); !got.Equal(From("go", "generics")) {
+		t.Fatalf("unexpected IntersectionAll %v", got)
+	}
+	if got := UnionAll(docs...); !got.Equal(From("go", "sets", "generics", "iterators", "maps")) {
+		t.Fatalf("unexpected UnionAll %v", got)
+	}
+	if !docs[2].Equal(From("generics", "go")) {
+		t.Fatalf("IntersectionAll modified its input %v", docs[2])
+	}
+	if got := IntersectionAll(append(docs, Set[string]{})...); len(got) != 0 {
+		t.Fatalf("unexpected IntersectionAll with an empty set %v", got)
+	}
+}
+
+func TestToSlice(t *testing.T) {
+	s := From(3, 1, 2)
+	vals := s.ToSlice()
+	if len(vals) != 3 || cap(vals) != 3 || !From(vals...).Equal(s) {
+		t.Fatalf("unexpected ToSlice %v", vals)
+	}
+	if got := ToSortedSlice(s); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("unexpected ToSortedSlice %v", got)
+	}
+	dst := s.AppendTo([]int{0})
+	if len(dst) != 4 || dst[0] != 0 || !From(dst[1:]...).Equal(s) {
+		t.Fatalf("unexpected AppendTo %v", dst)
+	}
+	if got := Set[int](nil).ToSlice(); got == nil || len(got) != 0 {
+		t.Fatalf("unexpected ToSlice of nil %v", got)
+	}
+
+	type header map[string][]string
+	h := header{"Accept": {"a"}, "Host": nil}
+	if keys := FromMapKeys(h); !keys.Equal(From("Accept", "Host")) {
+		t.Fatalf("unexpected FromMapKeys %v", keys)
+	}
+	type ids map[string]int
+	if vals := FromMapValues(ids{"a": 1, "b": 1}); !vals.Equal(From(1)) {
+		t.Fatalf("unexpected FromMapValues %v", vals)
+	}
+}
+
+func BenchmarkWithCapacity(b *testing.B) {
+	const n = 10000
+	b.Run("unsized", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			s := make(Set[int])
+			for i := range n {
+				s.Add(i)
+			}
+		}
+	})
+	b.Run("WithCapacity", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			s := WithCapacity[int](n)
+			for i := range n {
+				s.Add(i)
+			}
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package set
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Sorted returns an iterator over the values of s in ascending order, so output built from a Set is deterministic.
+// The values are sorted into a slice first.
+func Sorted[T cmp.Ordered](s Set[T]) iter.Seq[T] {
+	return slices.Values(slices.Sorted(maps.Keys(s)))
+}
+
+// SortedFunc is Sorted, but ordered by cmp like slices.SortFunc.
+func SortedFunc[T comparable](s Set[T], cmp func(a, b T) int) iter.Seq[T] {
+	return slices.Values(slices.SortedFunc(maps.Keys(s), cmp))
+}
+
+// ToSortedSlice returns the values of s in a new slice in ascending order.
+func ToSortedSlice[T cmp.Ordered](s Set[T]) []T {
+	vals := s.ToSlice()
+	slices.Sort(vals)
+	return vals
+}
+
+// String formats the Set like "set[a b c]". Values of a string, integer or float kind are sorted,
+// and other values are sorted by their formatting, so the output is stable for tests and debugging.
+func (s Set[T]) String() string {
+	vals := slices.Collect(maps.Keys(s))
+	sorted := sortValues(vals)
+	formatted := make([]string, len(vals))
+	for i, v := range vals {
+		formatted[i] = fmt.Sprint(v)
+	}
+	if !sorted {
+		slices.Sort(formatted)
+	}
+	return "set[" + strings.Join(formatted, " ") + "]"
+}
@@ -0,0 +1,39 @@
+package set
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSorted(t *testing.T) {
+	s := From(3, 1, 2, 10)
+	if got := slices.Collect(Sorted(s)); !slices.Equal(got, []int{1, 2, 3, 10}) {
+		t.Fatalf("unexpected Sorted %v", got)
+	}
+	desc := func(a, b int) int { return cmp.Compare(b, a) }
+	if got := slices.Collect(SortedFunc(s, desc)); !slices.Equal(got, []int{10, 3, 2, 1}) {
+		t.Fatalf("unexpected SortedFunc %v", got)
+	}
+	if got := slices.Collect(Sorted(Set[string](nil))); len(got) != 0 {
+		t.Fatalf("unexpected Sorted %v", got)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		set  fmt.Stringer
+		want string
+	}{
+		{From(3, 1, 10, 2), "set[1 2 3 10]"},
+		{From("b", "a"), "set[a b]"},
+		{From(testPoint{2, 1}, testPoint{1, 2}), "set[{1 2} {2 1}]"},
+		{Set[int](nil), "set[]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf("%v", tt.set); got != tt.want {
+			t.Fatalf("unexpected String %q, expected %q", got, tt.want)
+		}
+	}
+}
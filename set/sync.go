@@ -0,0 +1,130 @@
+package set
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// SyncSet is a Set guarded by a sync.RWMutex, so it's safe for concurrent use.
+// The zero value is an empty SyncSet ready to use. A SyncSet must not be copied after first use.
+type SyncSet[T comparable] struct {
+	mu sync.RWMutex
+	s  Set[T]
+}
+
+// SyncFrom creates a SyncSet containing vals.
+func SyncFrom[T comparable](vals ...T) *SyncSet[T] {
+	return &SyncSet[T]{s: From(vals...)}
+}
+
+// Add adds vals to the SyncSet and returns it.
+func (s *SyncSet[T]) Add(vals ...T) *SyncSet[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.s == nil {
+		s.s = make(Set[T], len(vals))
+	}
+	s.s.Add(vals...)
+	return s
+}
+
+// Has returns true if v is in the SyncSet.
+func (s *SyncSet[T]) Has(v T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.Has(v)
+}
+
+// HasAll returns true if every value from seq is in the SyncSet.
+// The lock is only held while checking each value, so seq may use the SyncSet.
+func (s *SyncSet[T]) HasAll(seq iter.Seq[T]) bool {
+	for v := range seq {
+		if !s.Has(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasAny returns true if any value from seq is in the SyncSet.
+// The lock is only held while checking each value, so seq may use the SyncSet.
+func (s *SyncSet[T]) HasAny(seq iter.Seq[T]) bool {
+	for v := range seq {
+		if s.Has(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// All returns an iterator over a Snapshot of the SyncSet, so the lock isn't held while ranging over it.
+// Changes made during iteration aren't seen.
+func (s *SyncSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range s.Snapshot() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Snapshot returns a copy of the SyncSet as a plain Set, for iterating or passing along without locking.
+func (s *SyncSet[T]) Snapshot() Set[T] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snap := maps.Clone(s.s)
+	if snap == nil {
+		snap = make(Set[T])
+	}
+	return snap
+}
+
+// Union adds every value from seq to the SyncSet and returns it.
+// seq is consumed before locking, so it may use the SyncSet.
+func (s *SyncSet[T]) Union(seq iter.Seq[T]) *SyncSet[T] {
+	return s.Add(slices.Collect(seq)...)
+}
+
+// Difference removes every value from seq from the SyncSet and returns it.
+// seq is consumed before locking, so it may use the SyncSet.
+func (s *SyncSet[T]) Difference(seq iter.Seq[T]) *SyncSet[T] {
+	vals := slices.Collect(seq)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Difference(slices.Values(vals))
+	return s
+}
+
+// Intersection returns a new SyncSet of the values from seq that are also in the SyncSet.
+// The lock is only held while checking each value, so seq may use the SyncSet.
+func (s *SyncSet[T]) Intersection(seq iter.Seq[T]) *SyncSet[T] {
+	inter := make(Set[T])
+	for v := range seq {
+		if s.Has(v) {
+			inter[v] = struct{}{}
+		}
+	}
+	return &SyncSet[T]{s: inter}
+}
+
+// Intersects returns a new SyncSet of the values from seq that are also in the SyncSet.
+//
+// Deprecated: Use Intersection, or IntersectsWith to check if there are any values in common.
+func (s *SyncSet[T]) Intersects(seq iter.Seq[T]) *SyncSet[T] {
+	return s.Intersection(seq)
+}
+
+// IntersectsWith returns true if any value from seq is in the SyncSet, stopping at the first value found in both.
+func (s *SyncSet[T]) IntersectsWith(seq iter.Seq[T]) bool {
+	return s.HasAny(seq)
+}
+
+// Len returns the number of values in the SyncSet.
+func (s *SyncSet[T]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.s)
+}
@@ -0,0 +1,72 @@
+package set
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestSyncSet(t *testing.T) {
+	var s SyncSet[int]
+	if s.Has(1) || s.Len() != 0 || len(s.Snapshot()) != 0 {
+		t.Fatal("expected the zero value to be empty")
+	}
+
+	s.Add(1, 2, 3).Union(slices.Values([]int{3, 4})).Difference(slices.Values([]int{1}))
+	if !s.HasAll(slices.Values([]int{2, 3, 4})) || s.HasAny(slices.Values([]int{1, 5})) || s.Len() != 3 {
+		t.Fatalf("unexpected values %v", s.Snapshot())
+	}
+	inter := s.Intersection(slices.Values([]int{1, 2, 4, 6}))
+	if !s.IntersectsWith(slices.Values([]int{6, 4})) || s.IntersectsWith(slices.Values([]int{1})) {
+		t.Fatalf("unexpected IntersectsWith of %v", s.Snapshot())
+	}
+	if inter.Len() != 2 || !inter.Has(2) || !inter.Has(4) {
+		t.Fatalf("unexpected intersection %v", inter.Snapshot())
+	}
+
+	// Iterating All doesn't hold the lock, so the SyncSet can be changed while ranging.
+	for v := range s.All() {
+		s.Add(v * 10)
+	}
+	if s.Len() != 6 {
+		t.Fatalf("unexpected values %v", s.Snapshot())
+	}
+	s.Union(s.All()).Difference(s.All())
+	if s.Len() != 0 {
+		t.Fatalf("expected seq from the SyncSet itself to work, got %v", s.Snapshot())
+	}
+
+	snap := SyncFrom(1).Snapshot()
+	snap.Add(2)
+	if !snap.Has(2) {
+		t.Fatal("expected the snapshot to be a usable Set")
+	}
+}
+
+func TestSyncSetConcurrent(t *testing.T) {
+	s := SyncFrom[int]()
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 200 {
+				v := g*1000 + i
+				s.Add(v)
+				if !s.Has(v) {
+					t.Errorf("expected %d after adding it", v)
+				}
+				s.Union(slices.Values([]int{v + 500}))
+				s.HasAny(s.All())
+				if i%10 == 0 {
+					s.Difference(slices.Values([]int{v + 500}))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if want := 8 * (200 + 180); s.Len() != want {
+		t.Fatalf("expected %d values, got %d", want, s.Len())
+	}
+}
@@ -0,0 +1,92 @@
+package set
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/danlock/pkg/retry"
+)
+
+// TTLSet is a set whose values expire ttl after they were last added, such as for deduplicating messages seen within a window.
+// Expired values count as absent, but they take up memory until Prune removes them, which Run can do in the background.
+// It's safe for concurrent use.
+type TTLSet[T comparable] struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	clock func() time.Time
+	added map[T]time.Time
+}
+
+// NewTTL creates an empty TTLSet whose values expire after ttl, according to clock. time.Now is used when clock is nil.
+func NewTTL[T comparable](ttl time.Duration, clock func() time.Time) *TTLSet[T] {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &TTLSet[T]{ttl: ttl, clock: clock, added: make(map[T]time.Time)}
+}
+
+// Add adds vals to the TTLSet and returns it. Values already present have their expiry pushed back by ttl.
+func (s *TTLSet[T]) Add(vals ...T) *TTLSet[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock()
+	for _, v := range vals {
+		s.added[v] = now
+	}
+	return s
+}
+
+// Has returns true if v is in the TTLSet and hasn't expired. A value expires exactly ttl after it was added.
+func (s *TTLSet[T]) Has(v T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	added, ok := s.added[v]
+	return ok && !s.expired(added, s.clock())
+}
+
+// Len returns the number of values in the TTLSet that haven't expired.
+func (s *TTLSet[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock()
+	n := 0
+	for _, added := range s.added {
+		if !s.expired(added, now) {
+			n++
+		}
+	}
+	return n
+}
+
+// Prune removes the expired values from the TTLSet, returning how many were removed.
+func (s *TTLSet[T]) Prune() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock()
+	pruned := 0
+	for v, added := range s.added {
+		if s.expired(added, now) {
+			delete(s.added, v)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// Run calls Prune every interval until ctx finishes, so expired values don't build up. It's meant to be run in its own goroutine.
+// It's built on retry.Poll. An interval that isn't positive is replaced by the ttl, or 1ms if that isn't positive either,
+// since pruning in a loop would hold the lock continuously.
+func (s *TTLSet[T]) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = max(s.ttl, time.Millisecond)
+	}
+	retry.Poll(ctx, interval, nil, func() error {
+		s.Prune()
+		return nil
+	})
+}
+
+func (s *TTLSet[T]) expired(added, now time.Time) bool {
+	return !now.Before(added.Add(s.ttl))
+}
@@ -0,0 +1,107 @@
+package set
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danlock/pkg/test"
+)
+
+// fakeClock is a clock for TTLSet that only moves when advanced.
+type fakeClock struct{ nanos atomic.Int64 }
+
+func (c *fakeClock) Now() time.Time          { return time.Unix(0, c.nanos.Load()) }
+func (c *fakeClock) Advance(d time.Duration) { c.nanos.Add(int64(d)) }
+
+func TestTTLSet(t *testing.T) {
+	var clock fakeClock
+	s := NewTTL[string](time.Minute, clock.Now)
+	s.Add("a", "b")
+	clock.Advance(30 * time.Second)
+	s.Add("c", "a")
+
+	clock.Advance(30*time.Second - time.Nanosecond)
+	if !s.Has("b") || s.Len() != 3 {
+		t.Fatalf("expected b to last until exactly its ttl, len %d", s.Len())
+	}
+	clock.Advance(time.Nanosecond)
+	if s.Has("b") || !s.Has("a") || !s.Has("c") || s.Len() != 2 {
+		t.Fatalf("expected b to expire exactly at its ttl, len %d", s.Len())
+	}
+
+	if pruned := s.Prune(); pruned != 1 {
+		t.Fatalf("expected 1 pruned, got %d", pruned)
+	}
+	clock.Advance(30 * time.Second)
+	if s.Has("a") || s.Len() != 0 {
+		t.Fatalf("expected everything to expire, len %d", s.Len())
+	}
+	if pruned := s.Prune(); pruned != 2 || s.Prune() != 0 {
+		t.Fatalf("expected 2 pruned, got %d", pruned)
+	}
+
+	// Expired values can be added again.
+	if !s.Add("b").Has("b") {
+		t.Fatal("expected b to be added again")
+	}
+}
+
+func TestTTLSetPruneUnderLoad(t *testing.T) {
+	var clock fakeClock
+	s := NewTTL[int](time.Second, clock.Now)
+	ctx, cancel := context.WithCancel(context.Background())
+	runDone := make(chan struct{})
+	go func() {
+		defer close(runDone)
+		s.Run(ctx, time.Millisecond)
+	}()
+
+	var wg sync.WaitGroup
+	for w := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				s.Add(w*1000 + i)
+				if i%100 == 0 {
+					clock.Advance(100 * time.Millisecond)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	clock.Advance(time.Second)
+	s.Add(-1)
+
+	test.AssertEventually(t, func() int {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.added)
+	}, 1, 5*time.Second, time.Millisecond, "expected Run to prune everything but the latest value")
+	if !s.Has(-1) || s.Len() != 1 {
+		t.Fatalf("expected only -1, len %d", s.Len())
+	}
+
+	cancel()
+	<-runDone
+}
+
+func TestTTLSetRunNonPositiveInterval(t *testing.T) {
+	var clock fakeClock
+	s := NewTTL[int](time.Millisecond, clock.Now)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var prunes atomic.Int32
+	s.clock = func() time.Time {
+		prunes.Add(1)
+		return clock.Now()
+	}
+	s.Run(ctx, 0)
+	if got := prunes.Load(); got > 60 {
+		t.Fatalf("expected Run to wait the ttl between prunes, pruned %d times", got)
+	}
+}
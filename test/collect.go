@@ -0,0 +1,32 @@
+package test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/danlock/pkg/errors"
+)
+
+// ErrorCollector returns an add func for the non-fatal errors of a test, such as from validating many records,
+// so the test keeps going past the first error. Once the test finishes, it fails once with every collected error joined by errors.Join.
+// nil errors are ignored, and add is safe to call from multiple goroutines.
+func ErrorCollector(t testing.TB) (add func(err error)) {
+	t.Helper()
+	var mu sync.Mutex
+	var errs []error
+	t.Cleanup(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(errs) > 0 {
+			t.Errorf("%d errors collected:\n%v", len(errs), errors.Join(errs...))
+		}
+	})
+	return func(err error) {
+		if err == nil {
+			return
+		}
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+}
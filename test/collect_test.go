@@ -0,0 +1,73 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+// fakeTB records the failures of the helpers under test instead of failing the real test.
+type fakeTB struct {
+	testing.TB
+	cleanups []func()
+	failures []string
+}
+
+func (f *fakeTB) Helper()           {}
+func (f *fakeTB) Cleanup(fn func()) { f.cleanups = append(f.cleanups, fn) }
+func (f *fakeTB) Error(args ...any) { f.failures = append(f.failures, fmt.Sprint(args...)) }
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+// Fatalf records the failure and ends the calling goroutine, so helpers that call it must be called within run.
+func (f *fakeTB) Fatalf(format string, args ...any) {
+	f.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// run calls fn in its own goroutine, so a Fatalf within it doesn't end the real test.
+func (f *fakeTB) run(fn func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	<-done
+}
+
+// finish runs the cleanups like the end of a test.
+func (f *fakeTB) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+func TestErrorCollector(t *testing.T) {
+	var tb fakeTB
+	add := ErrorCollector(&tb)
+	add(errors.New("record 1 is missing a name"))
+	add(nil)
+	add(errors.New("record 3 has a negative age"))
+	if len(tb.failures) != 0 {
+		t.Fatalf("expected no failures before the test finished, got %v", tb.failures)
+	}
+
+	tb.finish()
+	if len(tb.failures) != 1 {
+		t.Fatalf("expected a single failure, got %v", tb.failures)
+	}
+	want := "2 errors collected:\nrecord 1 is missing a name\nrecord 3 has a negative age"
+	if tb.failures[0] != want {
+		t.Fatalf("expected %q, got %q", want, tb.failures[0])
+	}
+
+	var clean fakeTB
+	add = ErrorCollector(&clean)
+	add(nil)
+	clean.finish()
+	if len(clean.failures) != 0 {
+		t.Fatalf("expected no failure without errors, got %v", clean.failures)
+	}
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// AssertEventually calls fn every poll until it returns expected, failing the test if it doesn't within timeout.
+// The failure reports both expected and the last value fn returned. msgs are printed before them.
+func AssertEventually[T comparable](t testing.TB, fn func() T, expected T, timeout, poll time.Duration, msgs ...any) {
+	t.Helper()
+	var last T
+	AssertEventuallyFunc(t, func() (bool, string) {
+		last = fn()
+		return last == expected, fmt.Sprintf("expected %v, last got %v", expected, last)
+	}, timeout, poll, msgs...)
+}
+
+// AssertEventuallyFunc calls fn every poll until it returns true, failing the test if it doesn't within timeout.
+// fn also returns a description of its state, which is reported from its last call on failure. msgs are printed before it.
+func AssertEventuallyFunc(t testing.TB, fn func() (ok bool, description string), timeout, poll time.Duration, msgs ...any) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, description := fn()
+		if ok {
+			return
+		}
+		if !time.Now().Before(deadline) {
+			if len(msgs) > 0 {
+				t.Fatalf("%s: not true after %v: %s", fmt.Sprint(msgs...), timeout, description)
+			}
+			t.Fatalf("not true after %v: %s", timeout, description)
+		}
+		time.Sleep(min(poll, time.Until(deadline)))
+	}
+}
+
+// AssertConsistently calls cond every poll for duration, failing the test with t.Errorf the first time it returns false,
+// such as to check that a goroutine count or metric stays stable. The failure reports which check failed. msgs are printed before it.
+func AssertConsistently(t testing.TB, cond func() bool, duration, poll time.Duration, msgs ...any) {
+	t.Helper()
+	deadline := time.Now().Add(duration)
+	for i := 1; ; i++ {
+		if !cond() {
+			if len(msgs) > 0 {
+				t.Errorf("%s: became false on check %d of the %v", fmt.Sprint(msgs...), i, duration)
+			} else {
+				t.Errorf("became false on check %d of the %v", i, duration)
+			}
+			return
+		}
+		if !time.Now().Before(deadline) {
+			return
+		}
+		time.Sleep(min(poll, time.Until(deadline)))
+	}
+}
+
+// Consistently is AssertConsistently, named as the complement of Eventually in other assertion libraries.
+func Consistently(t testing.TB, cond func() bool, duration, poll time.Duration, msgs ...any) {
+	t.Helper()
+	AssertConsistently(t, cond, duration, poll, msgs...)
+}
@@ -0,0 +1,88 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAssertConsistently(t *testing.T) {
+	var tb fakeTB
+	calls := 0
+	AssertConsistently(&tb, func() bool {
+		calls++
+		return calls < 3
+	}, time.Second, time.Millisecond, "lock held")
+	if calls != 3 {
+		t.Fatalf("expected cond to stop being polled after the first false, got %d calls", calls)
+	}
+	if len(tb.failures) != 1 || !strings.HasPrefix(tb.failures[0], "lock held: became false on check 3 ") {
+		t.Fatalf("unexpected failures %v", tb.failures)
+	}
+
+	var ok fakeTB
+	calls = 0
+	Consistently(&ok, func() bool {
+		calls++
+		return true
+	}, 20*time.Millisecond, time.Millisecond)
+	if len(ok.failures) != 0 || calls < 2 {
+		t.Fatalf("expected %d calls to pass, got %v", calls, ok.failures)
+	}
+}
+
+func TestAssertEventually(t *testing.T) {
+	var tb fakeTB
+	calls := 0
+	tb.run(func() {
+		AssertEventually(&tb, func() int {
+			calls++
+			return calls
+		}, 3, time.Second, time.Millisecond)
+	})
+	if len(tb.failures) != 0 || calls != 3 {
+		t.Fatalf("expected to pass on the 3rd call, got %d calls and %v", calls, tb.failures)
+	}
+
+	var timedOut fakeTB
+	returned := false
+	timedOut.run(func() {
+		AssertEventually(&timedOut, func() string { return "pending" }, "done", 20*time.Millisecond, time.Millisecond, "job ", 7)
+		returned = true
+	})
+	if returned {
+		t.Fatal("expected AssertEventually to stop the test after timing out")
+	}
+	if len(timedOut.failures) != 1 || !strings.HasPrefix(timedOut.failures[0], "job 7: not true after 20ms: ") ||
+		!strings.HasSuffix(timedOut.failures[0], "expected done, last got pending") {
+		t.Fatalf("unexpected failures %v", timedOut.failures)
+	}
+}
+
+func TestAssertEventuallyFunc(t *testing.T) {
+	var tb fakeTB
+	calls := 0
+	tb.run(func() {
+		AssertEventuallyFunc(&tb, func() (bool, string) {
+			calls++
+			return calls == 2, "not yet"
+		}, time.Second, time.Millisecond)
+	})
+	if len(tb.failures) != 0 || calls != 2 {
+		t.Fatalf("expected to pass on the 2nd call, got %d calls and %v", calls, tb.failures)
+	}
+
+	var timedOut fakeTB
+	calls = 0
+	timedOut.run(func() {
+		AssertEventuallyFunc(&timedOut, func() (bool, string) {
+			calls++
+			return false, fmt.Sprintf("%d calls", calls)
+		}, 20*time.Millisecond, time.Millisecond)
+	})
+	want := fmt.Sprintf("not true after 20ms: %d calls", calls)
+	if calls < 2 || len(timedOut.failures) != 1 || timedOut.failures[0] != want {
+		t.Fatalf("expected %q, got %v", want, timedOut.failures)
+	}
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// fuzzTypes are the types testing.F.Add supports.
+var fuzzTypes = map[reflect.Type]bool{
+	reflect.TypeFor[[]byte]():  true,
+	reflect.TypeFor[string]():  true,
+	reflect.TypeFor[bool]():    true,
+	reflect.TypeFor[float32](): true,
+	reflect.TypeFor[float64](): true,
+	reflect.TypeFor[int]():     true,
+	reflect.TypeFor[int8]():    true,
+	reflect.TypeFor[int16]():   true,
+	reflect.TypeFor[int32]():   true,
+	reflect.TypeFor[int64]():   true,
+	reflect.TypeFor[uint]():    true,
+	reflect.TypeFor[uint8]():   true,
+	reflect.TypeFor[uint16]():  true,
+	reflect.TypeFor[uint32]():  true,
+	reflect.TypeFor[uint64]():  true,
+}
+
+// FuzzSeed adds inputs to the seed corpus of f as a single entry, like f.Add(inputs...), so a fuzz target taking several arguments is seeded with
+//
+//	test.FuzzSeed(f, "a", 1)
+//
+// FuzzSeed panics with the offending input if its type isn't supported by f.Add,
+// rather than f.Add failing later without pointing at the input.
+func FuzzSeed(f *testing.F, inputs ...any) {
+	for i, input := range inputs {
+		checkFuzzType("FuzzSeed", i, "", reflect.ValueOf(input))
+	}
+	f.Helper()
+	f.Add(inputs...)
+}
+
+// FuzzSeedSlice adds each value to the seed corpus of f as a separate entry.
+// A struct value is spread into its exported fields in order, so a fuzz target taking several arguments can be seeded with
+//
+//	test.FuzzSeedSlice(f, []struct{ S string; N int }{{"a", 1}, {"b", 2}})
+//
+// Like FuzzSeed, it panics with the offending value if its type isn't supported by f.Add.
+func FuzzSeedSlice[T any](f *testing.F, values []T) {
+	seeds := make([][]any, len(values))
+	for i, v := range values {
+		seeds[i] = fuzzArgs(i, v)
+	}
+	f.Helper()
+	for _, args := range seeds {
+		f.Add(args...)
+	}
+}
+
+func fuzzArgs(i int, input any) []any {
+	v := reflect.ValueOf(input)
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		checkFuzzType("FuzzSeedSlice", i, "", v)
+		return []any{input}
+	}
+
+	args := make([]any, 0, v.NumField())
+	for f := range v.NumField() {
+		field := v.Type().Field(f)
+		if !field.IsExported() {
+			panic(fmt.Sprintf("test.FuzzSeedSlice input %d field %s is unexported", i, field.Name))
+		}
+		checkFuzzType("FuzzSeedSlice", i, field.Name, v.Field(f))
+		args = append(args, v.Field(f).Interface())
+	}
+	return args
+}
+
+func checkFuzzType(fn string, i int, field string, v reflect.Value) {
+	if v.IsValid() && fuzzTypes[v.Type()] {
+		return
+	}
+	name := "nil"
+	if v.IsValid() {
+		name = v.Type().String()
+	}
+	if field != "" {
+		field = " field " + field
+	}
+	panic(fmt.Sprintf("test.%s input %d%s has type %s, but testing.F.Add only supports []byte, string, bool, float and int types", fn, i, field, name))
+}
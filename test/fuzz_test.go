@@ -0,0 +1,45 @@
+package test
+
+import (
+	"strings"
+	"testing"
+)
+
+// FuzzFuzzSeed fails if the seeds don't match the arguments of the fuzz target.
+func FuzzFuzzSeed(f *testing.F) {
+	FuzzSeed(f, "a", 1)
+	FuzzSeed(f, "b", 2)
+	f.Fuzz(func(t *testing.T, s string, n int) {})
+}
+
+func FuzzFuzzSeedSlice(f *testing.F) {
+	FuzzSeedSlice(f, []struct {
+		S string
+		B []byte
+	}{{"a", []byte("1")}, {"b", nil}})
+	f.Fuzz(func(t *testing.T, s string, b []byte) {})
+}
+
+func TestFuzzSeedPanics(t *testing.T) {
+	// The types are checked before f is used, so a nil f is enough.
+	for _, tt := range []struct {
+		name string
+		fn   func()
+		want string
+	}{
+		{"FuzzSeed", func() { FuzzSeed(nil, "a", struct{}{}) }, "test.FuzzSeed input 1 has type struct {}"},
+		{"FuzzSeed nil", func() { FuzzSeed(nil, nil) }, "test.FuzzSeed input 0 has type nil"},
+		{"FuzzSeedSlice", func() { FuzzSeedSlice(nil, []uintptr{1}) }, "test.FuzzSeedSlice input 0 has type uintptr"},
+		{"FuzzSeedSlice field", func() { FuzzSeedSlice(nil, []struct{ M map[int]int }{{}}) }, "test.FuzzSeedSlice input 0 field M has type map[int]int"},
+		{"FuzzSeedSlice unexported", func() { FuzzSeedSlice(nil, []struct{ s string }{{}}) }, "test.FuzzSeedSlice input 0 field s is unexported"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if msg, _ := recover().(string); !strings.HasPrefix(msg, tt.want) {
+					t.Fatalf("expected a panic starting with %q, got %q", tt.want, msg)
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
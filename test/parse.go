@@ -0,0 +1,31 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+)
+
+// MustParse returns parse(input), failing the test if parse returns an error. msgs are printed before the error.
+//
+//	id := test.MustParse(t, "550e8400-e29b-41d4-a716-446655440000", uuid.Parse)
+func MustParse[T any](t testing.TB, input string, parse func(string) (T, error), msgs ...any) T {
+	v, err := parse(input)
+	if err != nil {
+		t.Helper()
+		if len(msgs) > 0 {
+			t.Fatalf("%s: parsing %q: %+v", fmt.Sprint(msgs...), input, err)
+		}
+		t.Fatalf("parsing %q: %+v", input, err)
+	}
+	return v
+}
+
+// MustParseAll is MustParse for every input, failing the test at the first input parse returns an error for.
+func MustParseAll[T any](t testing.TB, inputs []string, parse func(string) (T, error)) []T {
+	t.Helper()
+	vals := make([]T, len(inputs))
+	for i, input := range inputs {
+		vals[i] = MustParse(t, input, parse, "input ", i)
+	}
+	return vals
+}
@@ -0,0 +1,61 @@
+package test
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMustParse(t *testing.T) {
+	var tb fakeTB
+	var got int
+	tb.run(func() { got = MustParse(&tb, "42", strconv.Atoi) })
+	if len(tb.failures) != 0 || got != 42 {
+		t.Fatalf("expected 42, got %d and %v", got, tb.failures)
+	}
+
+	var bad fakeTB
+	returned := false
+	bad.run(func() {
+		MustParse(&bad, "forty two", strconv.Atoi, "user ", 3)
+		returned = true
+	})
+	if returned {
+		t.Fatal("expected MustParse to stop the test")
+	}
+	if len(bad.failures) != 1 || !strings.HasPrefix(bad.failures[0], `user 3: parsing "forty two": `) ||
+		!strings.Contains(bad.failures[0], "invalid syntax") {
+		t.Fatalf("unexpected failures %v", bad.failures)
+	}
+
+	var noMsgs fakeTB
+	noMsgs.run(func() { MustParse(&noMsgs, "x", strconv.Atoi) })
+	if len(noMsgs.failures) != 1 || !strings.HasPrefix(noMsgs.failures[0], `parsing "x": `) {
+		t.Fatalf("unexpected failures %v", noMsgs.failures)
+	}
+}
+
+func TestMustParseAll(t *testing.T) {
+	var tb fakeTB
+	var got []int
+	tb.run(func() { got = MustParseAll(&tb, []string{"1", "-2", "3"}, strconv.Atoi) })
+	if len(tb.failures) != 0 || !slices.Equal(got, []int{1, -2, 3}) {
+		t.Fatalf("unexpected values %v and failures %v", got, tb.failures)
+	}
+
+	var bad fakeTB
+	parsed := 0
+	bad.run(func() {
+		MustParseAll(&bad, []string{"1", "2", "three", "four"}, func(s string) (int, error) {
+			parsed++
+			return strconv.Atoi(s)
+		})
+	})
+	if parsed != 3 {
+		t.Fatalf("expected MustParseAll to stop at the first error, parsed %d", parsed)
+	}
+	if len(bad.failures) != 1 || !strings.HasPrefix(bad.failures[0], `input 2: parsing "three": `) {
+		t.Fatalf("unexpected failures %v", bad.failures)
+	}
+}
@@ -8,3 +8,15 @@ func FailOnError(t testing.TB, err error) {
 		t.Fatalf("%+v", err)
 	}
 }
+
+// Parallel calls t.Parallel and returns t, so a test can be marked parallel in one line.
+func Parallel(t *testing.T) *testing.T {
+	t.Parallel()
+	return t
+}
+
+// SubTest runs fn as a subtest of t called name, just like t.Run.
+func SubTest(t *testing.T, name string, fn func(t *testing.T)) bool {
+	t.Helper()
+	return t.Run(name, fn)
+}
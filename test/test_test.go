@@ -0,0 +1,41 @@
+package test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParallel(t *testing.T) {
+	resumed := false
+	t.Run("group", func(t *testing.T) {
+		t.Run("parallel", func(t *testing.T) {
+			if got := Parallel(t); got != t {
+				t.Errorf("expected Parallel to return t")
+			}
+			resumed = true
+		})
+		// A parallel subtest pauses until its parent's function returns.
+		if resumed {
+			t.Fatal("expected Parallel to mark the subtest parallel")
+		}
+	})
+	if !resumed {
+		t.Fatal("expected the parallel subtest to run after its parent")
+	}
+}
+
+func TestSubTest(t *testing.T) {
+	var sub *testing.T
+	ok := SubTest(t, "named", func(t *testing.T) { sub = t })
+	if !ok || sub == nil || sub == t || sub.Name() != t.Name()+"/named" {
+		t.Fatalf("expected a subtest called named, got %v", sub)
+	}
+
+	var names []string
+	for _, name := range []string{"a", "b"} {
+		SubTest(t, name, func(t *testing.T) { names = append(names, t.Name()) })
+	}
+	if strings.Join(names, ",") != "TestSubTest/a,TestSubTest/b" {
+		t.Fatalf("unexpected subtests %v", names)
+	}
+}